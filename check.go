@@ -0,0 +1,662 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Check is a CHECK constraint of a table, with its definition as returned by
+// pg_get_constraintdef, e.g. 'CHECK ((amount > 0))'.
+type Check struct {
+	Name       string `json:"name"`
+	Definition string `json:"definition"`
+}
+
+// DefinitionLines returns the definition split in lines, for use in Go
+// comments; pg_get_constraintdef keeps the newlines of string literals.
+func (c *Check) DefinitionLines() []string {
+	return commentLines(c.Definition)
+}
+
+// Expression returns the boolean expression of the constraint.
+func (c *Check) Expression() string {
+	e := strings.TrimSuffix(strings.TrimSpace(c.Definition), " NOT VALID")
+	e = strings.TrimPrefix(e, "CHECK ")
+	return strings.TrimSpace(e)
+}
+
+// Validation is a CHECK constraint translated to Go. Cond is a boolean Go
+// expression over the fields of a model, true when the model violates the
+// constraint.
+type Validation struct {
+	Check *Check
+	Cond  string
+}
+
+// Validations returns the CHECK constraints of t which translate to Go,
+// written against a model named recv.
+func (t *Table) Validations(recv string) []Validation {
+	var vv []Validation
+	for _, c := range t.Checks {
+		if cond, ok := translateCheck(t, recv, c.Expression()); ok {
+			vv = append(vv, Validation{Check: c, Cond: cond})
+		}
+	}
+	return vv
+}
+
+// UntranslatedChecks returns the CHECK constraints of t which don't
+// translate to Go and are only enforced by the database.
+func (t *Table) UntranslatedChecks() []*Check {
+	var cc []*Check
+	for _, c := range t.Checks {
+		if _, ok := translateCheck(t, "m", c.Expression()); !ok {
+			cc = append(cc, c)
+		}
+	}
+	return cc
+}
+
+// checkFields maps the types supported in translated checks to the field
+// of their pgtype value holding the Go value, and the kind of that value.
+var checkFields = map[string][2]string{
+	"int2":    {"Int", "int"},
+	"int4":    {"Int", "int"},
+	"int8":    {"Int", "int"},
+	"float4":  {"Float", "float"},
+	"float8":  {"Float", "float"},
+	"text":    {"String", "string"},
+	"varchar": {"String", "string"},
+	"bool":    {"Bool", "bool"},
+}
+
+var checkNumericCasts = map[string]bool{
+	"smallint":         true,
+	"integer":          true,
+	"bigint":           true,
+	"numeric":          true,
+	"real":             true,
+	"double precision": true,
+}
+
+// checkNode is a node of a parsed check expression. Following SQL, a check
+// passes unless its expression is false, and an expression over a NULL is
+// neither true nor false. isTrue and isFalse return Go expressions telling
+// whether the node is definitely true or definitely false.
+type checkNode interface {
+	isTrue() string
+	isFalse() string
+}
+
+// checkValue is an operand of a comparison. status holds the Status of the
+// fields it reads, and is empty for literals.
+type checkValue struct {
+	expr    string
+	kind    string
+	goType  string
+	status  []string
+	literal bool
+}
+
+type checkCompare struct {
+	left, right checkValue
+	op          string
+}
+
+func (c *checkCompare) cond() string {
+	return c.left.expr + " " + c.op + " " + c.right.expr
+}
+
+// presence returns cond guarded by the fields of ss being Present.
+func presence(ss []string, cond string) string {
+	var cc []string
+	for _, s := range ss {
+		cc = append(cc, s+" == pgtype.Present")
+	}
+	return "(" + strings.Join(append(cc, cond), " && ") + ")"
+}
+
+func (c *checkCompare) isTrue() string {
+	return presence(append(c.left.status, c.right.status...), c.cond())
+}
+
+func (c *checkCompare) isFalse() string {
+	return presence(append(c.left.status, c.right.status...), "!("+c.cond()+")")
+}
+
+// checkList is 'v = ANY (ARRAY[...])' or 'v <> ALL (ARRAY[...])'.
+type checkList struct {
+	value  checkValue
+	in     bool
+	values []checkValue
+}
+
+func (c *checkList) cond() string {
+	var cc []string
+	for _, v := range c.values {
+		cc = append(cc, c.value.expr+" == "+v.expr)
+	}
+	return "(" + strings.Join(cc, " || ") + ")"
+}
+
+func (c *checkList) isTrue() string {
+	if c.in {
+		return presence(c.value.status, c.cond())
+	}
+	return presence(c.value.status, "!"+c.cond())
+}
+
+func (c *checkList) isFalse() string {
+	if c.in {
+		return presence(c.value.status, "!"+c.cond())
+	}
+	return presence(c.value.status, c.cond())
+}
+
+// checkIsNull is 'v IS NULL' or 'v IS NOT NULL'. A field is definitely
+// null when its Status is Null and definitely not null when it is Present;
+// an Undefined field leaves the test unknown, so the check passes.
+type checkIsNull struct {
+	status string
+	not    bool
+}
+
+func (c *checkIsNull) isTrue() string {
+	if c.not {
+		return "(" + c.status + " == pgtype.Present)"
+	}
+	return "(" + c.status + " == pgtype.Null)"
+}
+
+func (c *checkIsNull) isFalse() string {
+	if c.not {
+		return "(" + c.status + " == pgtype.Null)"
+	}
+	return "(" + c.status + " == pgtype.Present)"
+}
+
+type checkBool struct {
+	op          string
+	left, right checkNode
+}
+
+func (c *checkBool) isTrue() string {
+	if c.op == "AND" {
+		return "(" + c.left.isTrue() + " && " + c.right.isTrue() + ")"
+	}
+	return "(" + c.left.isTrue() + " || " + c.right.isTrue() + ")"
+}
+
+func (c *checkBool) isFalse() string {
+	if c.op == "AND" {
+		return "(" + c.left.isFalse() + " || " + c.right.isFalse() + ")"
+	}
+	return "(" + c.left.isFalse() + " && " + c.right.isFalse() + ")"
+}
+
+type checkNot struct {
+	node checkNode
+}
+
+func (c *checkNot) isTrue() string  { return c.node.isFalse() }
+func (c *checkNot) isFalse() string { return c.node.isTrue() }
+
+// checkBoolValue is a boolean column used as a condition by itself.
+type checkBoolValue struct {
+	value checkValue
+}
+
+func (c *checkBoolValue) isTrue() string { return presence(c.value.status, c.value.expr) }
+func (c *checkBoolValue) isFalse() string {
+	return presence(c.value.status, "!"+c.value.expr)
+}
+
+// translateCheck translates a check expression of t to a Go condition true
+// when a model named recv violates it. Only comparisons of supported columns
+// and literals, IN and ANY/ALL lists, IS NULL tests, char_length and boolean
+// logic are translated.
+func translateCheck(t *Table, recv, expr string) (cond string, ok bool) {
+	defer func() {
+		if r := recover(); r != nil {
+			cond, ok = "", false
+		}
+	}()
+	p := &checkParser{table: t, recv: recv, tokens: tokenizeCheck(expr)}
+	n := p.parseOr()
+	if p.pos != len(p.tokens) {
+		return "", false
+	}
+	return n.isFalse(), true
+}
+
+// checkParser is a recursive descent parser for check expressions. It
+// panics on anything it doesn't understand; translateCheck recovers.
+type checkParser struct {
+	table  *Table
+	recv   string
+	tokens []string
+	pos    int
+}
+
+func (p *checkParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *checkParser) next() string {
+	t := p.peek()
+	if t == "" {
+		panic("unexpected end of check expression")
+	}
+	p.pos++
+	return t
+}
+
+func (p *checkParser) expect(t string) {
+	if !strings.EqualFold(p.next(), t) {
+		panic("expected " + t)
+	}
+}
+
+func (p *checkParser) accept(t string) bool {
+	if strings.EqualFold(p.peek(), t) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *checkParser) parseOr() checkNode {
+	n := p.parseAnd()
+	for p.accept("OR") {
+		n = &checkBool{op: "OR", left: n, right: p.parseAnd()}
+	}
+	return n
+}
+
+func (p *checkParser) parseAnd() checkNode {
+	n := p.parseNot()
+	for p.accept("AND") {
+		n = &checkBool{op: "AND", left: n, right: p.parseNot()}
+	}
+	return n
+}
+
+func (p *checkParser) parseNot() checkNode {
+	if p.accept("NOT") {
+		return &checkNot{node: p.parseNot()}
+	}
+	return p.parsePredicate()
+}
+
+// parsePredicate parses a parenthesized condition, or a predicate on a
+// value. A parenthesized value such as '(status)::text' is told apart from a
+// parenthesized condition by what follows the closing parenthesis.
+func (p *checkParser) parsePredicate() checkNode {
+	if p.peek() == "(" {
+		save := p.pos
+		p.next()
+		n := p.tryCondition()
+		if n != nil && p.accept(")") && !p.isValueSuffix() {
+			return n
+		}
+		p.pos = save
+	}
+	v := p.parseValue()
+	switch op := p.peek(); op {
+	case "=", "<>", "!=", "<", "<=", ">", ">=":
+		p.next()
+		if p.accept("ANY") || (op == "<>" && p.accept("ALL")) {
+			return p.parseList(v, op == "=")
+		}
+		return p.compare(v, op, p.parseValue())
+	}
+	if p.accept("IN") {
+		return p.parseInList(v, true)
+	}
+	if strings.EqualFold(p.peek(), "NOT") && p.pos+1 < len(p.tokens) && strings.EqualFold(p.tokens[p.pos+1], "IN") {
+		p.pos += 2
+		return p.parseInList(v, false)
+	}
+	if p.accept("IS") {
+		not := p.accept("NOT")
+		p.expect("NULL")
+		if len(v.status) != 1 {
+			panic("IS NULL on an expression")
+		}
+		return &checkIsNull{status: v.status[0], not: not}
+	}
+	if v.kind == "bool" && !v.literal {
+		return &checkBoolValue{value: v}
+	}
+	panic("unsupported predicate")
+}
+
+func (p *checkParser) tryCondition() (n checkNode) {
+	save := p.pos
+	defer func() {
+		if r := recover(); r != nil {
+			p.pos = save
+			n = nil
+		}
+	}()
+	return p.parseOr()
+}
+
+func (p *checkParser) isValueSuffix() bool {
+	switch p.peek() {
+	case "::", "=", "<>", "!=", "<", "<=", ">", ">=":
+		return true
+	}
+	return strings.EqualFold(p.peek(), "IS")
+}
+
+func (p *checkParser) compare(l checkValue, op string, r checkValue) checkNode {
+	if !compatibleKinds(&l, &r) {
+		panic("incompatible operands")
+	}
+	if l.kind == "string" || l.kind == "bool" {
+		if op != "=" && op != "<>" && op != "!=" {
+			panic("ordering of strings and booleans depends on the database")
+		}
+	}
+	if l.literal && r.literal {
+		panic("comparison of literals")
+	}
+	switch op {
+	case "=":
+		op = "=="
+	case "<>":
+		op = "!="
+	}
+	return &checkCompare{left: l, right: r, op: op}
+}
+
+// compatibleKinds reports whether two values compare in Go, widening
+// integer literals compared to floats. Two non-literal values must have the
+// same Go type; an int2 and an int8 field don't compare without a conversion.
+// An integer literal must fit the type of the field it is compared to, or
+// the generated code wouldn't compile.
+func compatibleKinds(l, r *checkValue) bool {
+	if l.kind == r.kind {
+		if l.literal != r.literal {
+			return literalFits(l, r) && literalFits(r, l)
+		}
+		return l.literal || l.goType == r.goType
+	}
+	if l.kind == "int" && l.literal && r.kind == "float" {
+		l.kind = "float"
+		return true
+	}
+	if r.kind == "int" && r.literal && l.kind == "float" {
+		r.kind = "float"
+		return true
+	}
+	return false
+}
+
+// intBits are the sizes of the Go integers of integer columns.
+var intBits = map[string]int{"int2": 16, "int4": 32, "int8": 64}
+
+// literalFits reports whether lit, if it is a numeric literal, is in the
+// range of the Go type of v.
+func literalFits(lit, v *checkValue) bool {
+	if !lit.literal {
+		return true
+	}
+	switch {
+	case lit.kind == "int":
+		if bits, ok := intBits[v.goType]; ok {
+			_, err := strconv.ParseInt(lit.expr, 10, bits)
+			return err == nil
+		}
+	case lit.kind == "float" && v.goType == "float4":
+		_, err := strconv.ParseFloat(lit.expr, 32)
+		return err == nil
+	}
+	return true
+}
+
+func (p *checkParser) parseList(v checkValue, in bool) checkNode {
+	p.expect("(")
+	paren := p.accept("(")
+	p.expect("ARRAY")
+	p.expect("[")
+	l := &checkList{value: v, in: in}
+	for {
+		e := p.parseValue()
+		if !compatibleKinds(&v, &e) || !e.literal {
+			panic("unsupported list element")
+		}
+		l.values = append(l.values, e)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect("]")
+	if paren {
+		p.expect(")")
+	}
+	p.skipCasts()
+	p.expect(")")
+	if v.kind != "string" && v.kind != "int" && v.kind != "float" {
+		panic("unsupported list type")
+	}
+	return l
+}
+
+// parseInList parses '(a, b, ...)' following 'v IN' or 'v NOT IN', as
+// written in DDL; the server reports these as '= ANY' and '<> ALL'.
+func (p *checkParser) parseInList(v checkValue, in bool) checkNode {
+	p.expect("(")
+	l := &checkList{value: v, in: in}
+	for {
+		e := p.parseValue()
+		if !compatibleKinds(&v, &e) || !e.literal {
+			panic("unsupported list element")
+		}
+		l.values = append(l.values, e)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	if v.kind != "string" && v.kind != "int" && v.kind != "float" {
+		panic("unsupported list type")
+	}
+	return l
+}
+
+// parseValue parses a column, a literal or a length function call, followed
+// by any number of casts.
+func (p *checkParser) parseValue() checkValue {
+	var v checkValue
+	switch t := p.next(); {
+	case t == "(":
+		v = p.parseValue()
+		p.expect(")")
+	case t == "-":
+		v = p.parseValue()
+		if !v.literal || (v.kind != "int" && v.kind != "float") {
+			panic("negation of a non-literal")
+		}
+		v.expr = "-" + v.expr
+	case strings.HasPrefix(t, "'"):
+		s := strings.Replace(t[1:len(t)-1], "''", "'", -1)
+		v = checkValue{expr: strconv.Quote(s), kind: "string", literal: true}
+		if cast := p.skipCasts(); checkNumericCasts[cast] {
+			v = numericLiteral(s)
+		}
+		return v
+	case t[0] >= '0' && t[0] <= '9':
+		v = numericLiteral(t)
+	case strings.EqualFold(t, "true") || strings.EqualFold(t, "false"):
+		v = checkValue{expr: strings.ToLower(t), kind: "bool", literal: true}
+	case isCheckIdent(t) && p.peek() == "(":
+		v = p.parseFunc(strings.ToLower(t))
+	case isCheckIdent(t):
+		v = p.column(t)
+	default:
+		panic("unsupported value " + t)
+	}
+	p.skipCasts()
+	return v
+}
+
+// numericLiteral returns the Go literal of the number s. It is reformatted,
+// as Go reads integers with leading zeros, such as 010, as octal.
+func numericLiteral(s string) checkValue {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return checkValue{expr: strconv.FormatInt(n, 10), kind: "int", literal: true}
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		return checkValue{expr: strconv.FormatFloat(f, 'g', -1, 64), kind: "float", literal: true}
+	}
+	panic("invalid number " + s)
+}
+
+func (p *checkParser) parseFunc(name string) checkValue {
+	p.expect("(")
+	v := p.parseValue()
+	p.expect(")")
+	if v.kind != "string" || v.literal {
+		panic("unsupported function argument")
+	}
+	switch name {
+	case "char_length", "character_length", "length":
+		return checkValue{expr: "utf8.RuneCountInString(" + v.expr + ")", kind: "int", goType: "int", status: v.status}
+	case "octet_length":
+		return checkValue{expr: "len(" + v.expr + ")", kind: "int", goType: "int", status: v.status}
+	}
+	panic("unsupported function " + name)
+}
+
+func (p *checkParser) column(name string) checkValue {
+	name = strings.Trim(name, `"`)
+	c := p.table.Column(name)
+	if c == nil {
+		panic("unknown column " + name)
+	}
+	f, ok := checkFields[c.DataType]
+	if !ok || (c.TypeSchema != "" && c.TypeSchema != "pg_catalog") {
+		panic("unsupported column type " + c.DataType)
+	}
+	field := p.recv + "." + c.ExportedName()
+	return checkValue{expr: field + "." + f[0], kind: f[1], goType: c.DataType, status: []string{field + ".Status"}}
+}
+
+// skipCasts skips '::type' casts and returns the last type cast to.
+func (p *checkParser) skipCasts() string {
+	var cast string
+	for p.accept("::") {
+		var words []string
+		for isCheckIdent(p.peek()) && !isCheckKeyword(p.peek()) {
+			words = append(words, strings.ToLower(p.next()))
+		}
+		if p.accept("(") {
+			for !p.accept(")") {
+				p.next()
+			}
+		}
+		for p.accept("[") {
+			p.expect("]")
+			words = append(words, "[]")
+		}
+		cast = strings.Join(words, " ")
+	}
+	return cast
+}
+
+func isCheckKeyword(t string) bool {
+	switch strings.ToUpper(t) {
+	case "AND", "OR", "NOT", "IS", "NULL", "ANY", "ALL", "ARRAY":
+		return true
+	}
+	return false
+}
+
+func isCheckIdent(t string) bool {
+	if t == "" {
+		return false
+	}
+	if t[0] == '"' {
+		return true
+	}
+	r := rune(t[0])
+	return unicode.IsLetter(r) || r == '_'
+}
+
+// tokenizeCheck splits a check expression into identifiers, quoted
+// identifiers, string literals, numbers and operators.
+func tokenizeCheck(s string) []string {
+	var tt []string
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n':
+			i++
+		case c == '\'' || c == '"':
+			j := i + 1
+			for j < len(s) {
+				if s[j] == c {
+					if j+1 < len(s) && s[j+1] == c {
+						j += 2
+						continue
+					}
+					break
+				}
+				j++
+			}
+			if j >= len(s) {
+				panic(fmt.Sprintf("unterminated quote in %q", s))
+			}
+			tt = append(tt, s[i:j+1])
+			i = j + 1
+		case c >= '0' && c <= '9':
+			j := i
+			for j < len(s) && (s[j] >= '0' && s[j] <= '9' || s[j] == '.' || s[j] == 'e' || s[j] == 'E') {
+				j++
+			}
+			tt = append(tt, s[i:j])
+			i = j
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			j := i
+			for j < len(s) && (s[j] == '_' || s[j] == '$' || s[j] >= '0' && s[j] <= '9' || unicode.IsLetter(rune(s[j])) || s[j] >= 0x80) {
+				j++
+			}
+			tt = append(tt, s[i:j])
+			i = j
+		default:
+			for _, op := range []string{"::", "<=", ">=", "<>", "!="} {
+				if strings.HasPrefix(s[i:], op) {
+					tt = append(tt, op)
+					i += len(op)
+					goto next
+				}
+			}
+			tt = append(tt, string(c))
+			i++
+		next:
+		}
+	}
+	return tt
+}
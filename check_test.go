@@ -0,0 +1,203 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import "testing"
+
+func checkTable() *Table {
+	col := func(name, typ string) *Column {
+		return &Column{Name: name, DataType: typ, TypeSchema: "pg_catalog", Nullable: true}
+	}
+	return &Table{Schema: "public", Name: "customers", Columns: []*Column{
+		col("id", "int4"),
+		col("small", "int2"),
+		col("big", "int8"),
+		col("score", "float8"),
+		col("ratio", "float4"),
+		col("name", "text"),
+		col("email", "text"),
+		col("phone", "text"),
+		col("status", "varchar"),
+		col("active", "bool"),
+		{Name: "mood", DataType: "mood", TypeSchema: "public"},
+	}}
+}
+
+func TestTranslateCheck(t *testing.T) {
+	tests := []struct {
+		name string
+		expr string
+		// cond is the expected condition, or "" when the check doesn't
+		// translate.
+		cond string
+	}{
+		{
+			name: "comparison",
+			expr: "(id > 0)",
+			cond: "(m.ID.Status == pgtype.Present && !(m.ID.Int > 0))",
+		},
+		{
+			name: "comparison of columns",
+			expr: "(big > 0) AND (id <> 7)",
+			cond: "((m.Big.Status == pgtype.Present && !(m.Big.Int > 0)) || (m.ID.Status == pgtype.Present && !(m.ID.Int != 7)))",
+		},
+		{
+			name: "float column and integer literal",
+			expr: "((score >= (0)::double precision) AND (score <= '1.5'::double precision))",
+			cond: "((m.Score.Status == pgtype.Present && !(m.Score.Float >= 0)) || (m.Score.Status == pgtype.Present && !(m.Score.Float <= 1.5)))",
+		},
+		{
+			name: "string length",
+			expr: "(char_length(name) <= 80)",
+			cond: "(m.Name.Status == pgtype.Present && !(utf8.RuneCountInString(m.Name.String) <= 80))",
+		},
+		{
+			name: "any array",
+			expr: "((status)::text = ANY ((ARRAY['new'::character varying, 'vip'::character varying])::text[]))",
+			cond: `(m.Status.Status == pgtype.Present && !(m.Status.String == "new" || m.Status.String == "vip"))`,
+		},
+		{
+			name: "not in",
+			expr: "(name NOT IN ('a  b', 'it''s'))",
+			cond: `(m.Name.Status == pgtype.Present && (m.Name.String == "a  b" || m.Name.String == "it's"))`,
+		},
+		{
+			name: "boolean column",
+			expr: "(active OR (id IS NOT NULL))",
+			cond: "((m.Active.Status == pgtype.Present && !m.Active.Bool) && (m.ID.Status == pgtype.Null))",
+		},
+		{
+			name: "is not null",
+			expr: "((email IS NOT NULL) OR (phone IS NOT NULL))",
+			cond: "((m.Email.Status == pgtype.Null) && (m.Phone.Status == pgtype.Null))",
+		},
+		{
+			name: "is null",
+			expr: "(NOT (email IS NULL))",
+			cond: "(m.Email.Status == pgtype.Null)",
+		},
+		{
+			name: "int2 literal in range",
+			expr: "(small < 32767)",
+			cond: "(m.Small.Status == pgtype.Present && !(m.Small.Int < 32767))",
+		},
+		{
+			name: "negative int2 literal in range",
+			expr: "(small >= '-32768'::integer)",
+			cond: "(m.Small.Status == pgtype.Present && !(m.Small.Int >= -32768))",
+		},
+		{
+			name: "int2 literal out of range",
+			expr: "(small < 100000)",
+		},
+		{
+			name: "negative int2 literal out of range",
+			expr: "(small > -40000)",
+		},
+		{
+			name: "int4 literal out of range",
+			expr: "(id < 3000000000)",
+		},
+		{
+			name: "int4 list element out of range",
+			expr: "(id = ANY (ARRAY[1, 3000000000]))",
+		},
+		{
+			name: "int8 literal in range",
+			expr: "(big < 3000000000)",
+			cond: "(m.Big.Status == pgtype.Present && !(m.Big.Int < 3000000000))",
+		},
+		{
+			name: "integer literal with leading zeros",
+			expr: "(id > 010)",
+			cond: "(m.ID.Status == pgtype.Present && !(m.ID.Int > 10))",
+		},
+		{
+			name: "float literal with leading zeros",
+			expr: "(score >= '007.50'::double precision)",
+			cond: "(m.Score.Status == pgtype.Present && !(m.Score.Float >= 7.5))",
+		},
+		{
+			name: "float4 literal in range",
+			expr: "(ratio < '1.5'::real)",
+			cond: "(m.Ratio.Status == pgtype.Present && !(m.Ratio.Float < 1.5))",
+		},
+		{
+			name: "float4 literal out of range",
+			expr: "(ratio < '1e39'::real)",
+		},
+		{
+			name: "negative float4 literal out of range",
+			expr: "(ratio > '-1e39'::real)",
+		},
+		{
+			name: "float8 literal in range",
+			expr: "(score < '1e39'::double precision)",
+			cond: "(m.Score.Status == pgtype.Present && !(m.Score.Float < 1e+39))",
+		},
+		{
+			name: "columns of different types",
+			expr: "(big > id)",
+		},
+		{
+			name: "ordering of strings",
+			expr: "(name > 'a'::text)",
+		},
+		{
+			name: "regular expression",
+			expr: "(name ~ '^[a-z]+$'::text)",
+		},
+		{
+			name: "enum column",
+			expr: "(mood <> 'sad'::mood)",
+		},
+		{
+			name: "unknown column",
+			expr: "(missing > 0)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cond, ok := translateCheck(checkTable(), "m", tt.expr)
+			if tt.cond == "" {
+				if ok {
+					t.Fatalf("translateCheck(%q) = %q, want untranslated", tt.expr, cond)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("translateCheck(%q) didn't translate, want %q", tt.expr, tt.cond)
+			}
+			if cond != tt.cond {
+				t.Errorf("translateCheck(%q) =\n\t%s\nwant\n\t%s", tt.expr, cond, tt.cond)
+			}
+		})
+	}
+}
+
+func TestCheckExpression(t *testing.T) {
+	tests := []struct {
+		def, want string
+	}{
+		{"CHECK ((amount > 0))", "((amount > 0))"},
+		{"CHECK ((amount > 0)) NOT VALID", "((amount > 0))"},
+	}
+	for _, tt := range tests {
+		c := &Check{Definition: tt.def}
+		if got := c.Expression(); got != tt.want {
+			t.Errorf("Expression(%q) = %q, want %q", tt.def, got, tt.want)
+		}
+	}
+}
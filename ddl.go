@@ -0,0 +1,1610 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+)
+
+// ParseDDL reads the tables, enums, composite types, domains and sequences
+// in schemas from SQL DDL, such as the output of pg_dump --schema-only, into the same
+// structure Inspect returns, along with the signatures of functions and
+// procedures. Views are skipped, as are the statements which don't change
+// the shape of the tables.
+//
+// Expressions, e.g. defaults and CHECK constraints, are kept as written
+// rather than in the server's normalized form, except that whitespace and
+// comments between their tokens are reduced to single spaces.
+func ParseDDL(src string, schemas ...string) (data *PGData, err error) {
+	p := &ddlParser{
+		src:        src,
+		schema:     "public",
+		enums:      map[string]*Enum{},
+		composites: map[string]*Composite{},
+		domains:    map[string]*Domain{},
+		tables:     map[string]*Table{},
+		functions:  map[string]*Function{},
+		sequences:  map[string]*Sequence{},
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(ddlError)
+			if !ok {
+				panic(r)
+			}
+			data, err = nil, e
+		}
+	}()
+	p.toks = p.tokenize()
+	for p.pos < len(p.toks) {
+		p.statement()
+	}
+	return p.finish(schemas)
+}
+
+type ddlError struct {
+	line int
+	msg  string
+}
+
+func (e ddlError) Error() string {
+	return fmt.Sprintf("line %d: %s", e.line, e.msg)
+}
+
+const (
+	ddlIdent = iota
+	ddlQuoted
+	ddlString
+	ddlNumber
+	ddlOp
+)
+
+type ddlToken struct {
+	kind int
+	// text is lower cased for identifiers, and unquoted for quoted
+	// identifiers and strings.
+	text       string
+	start, end int
+}
+
+// ddlIndex is an index, or primary key or unique constraint, whose columns
+// are resolved once all statements are read.
+type ddlIndex struct {
+	table *Table
+	index *Index
+	keys  []string
+}
+
+// ddlForeignKey is a foreign key whose columns are resolved once all
+// statements are read; RefColumns default to the referenced primary key.
+type ddlForeignKey struct {
+	table *Table
+	fk    *ForeignKey
+	cols  []string
+}
+
+type ddlParser struct {
+	src  string
+	toks []ddlToken
+	pos  int
+	// schema is the schema of unqualified names, the first of search_path.
+	schema string
+	// typmod is the modifier of the type last read, e.g. '10, 2' for
+	// numeric(10, 2).
+	typmod string
+
+	enums       map[string]*Enum
+	composites  map[string]*Composite
+	domains     map[string]*Domain
+	tables      map[string]*Table
+	functions   map[string]*Function
+	sequences   map[string]*Sequence
+	indexes     []ddlIndex
+	foreignKeys []ddlForeignKey
+}
+
+func (p *ddlParser) fail(format string, args ...interface{}) {
+	p.failAt(p.peek().start, format, args...)
+}
+
+func (p *ddlParser) failAt(off int, format string, args ...interface{}) {
+	panic(ddlError{line: strings.Count(p.src[:off], "\n") + 1, msg: fmt.Sprintf(format, args...)})
+}
+
+// tokenize splits the source into identifiers, strings, numbers and
+// operators, dropping whitespace and comments.
+func (p *ddlParser) tokenize() []ddlToken {
+	var tt []ddlToken
+	s := p.src
+	for i := 0; i < len(s); {
+		c := s[i]
+		start := i
+		switch {
+		case unicode.IsSpace(rune(c)):
+			i++
+			continue
+		case strings.HasPrefix(s[i:], "--"):
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+			continue
+		case strings.HasPrefix(s[i:], "/*"):
+			depth := 0
+			for i < len(s) {
+				if strings.HasPrefix(s[i:], "/*") {
+					depth++
+					i += 2
+				} else if strings.HasPrefix(s[i:], "*/") {
+					depth--
+					i += 2
+					if depth == 0 {
+						break
+					}
+				} else {
+					i++
+				}
+			}
+			continue
+		case c == '\'' || (c == 'E' || c == 'e') && i+1 < len(s) && s[i+1] == '\'':
+			escapes := c != '\''
+			if escapes {
+				i++
+			}
+			var b []byte
+			for i++; ; i++ {
+				if i >= len(s) {
+					p.failAt(start, "unterminated string")
+				}
+				if escapes && s[i] == '\\' && i+1 < len(s) {
+					i++
+					switch s[i] {
+					case 'n':
+						b = append(b, '\n')
+					case 't':
+						b = append(b, '\t')
+					default:
+						b = append(b, s[i])
+					}
+					continue
+				}
+				if s[i] == '\'' {
+					if i+1 < len(s) && s[i+1] == '\'' {
+						b = append(b, '\'')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b = append(b, s[i])
+			}
+			tt = append(tt, ddlToken{kind: ddlString, text: string(b), start: start, end: i})
+		case c == '"':
+			var b []byte
+			for i++; ; i++ {
+				if i >= len(s) {
+					p.failAt(start, "unterminated quoted identifier")
+				}
+				if s[i] == '"' {
+					if i+1 < len(s) && s[i+1] == '"' {
+						b = append(b, '"')
+						i++
+						continue
+					}
+					i++
+					break
+				}
+				b = append(b, s[i])
+			}
+			tt = append(tt, ddlToken{kind: ddlQuoted, text: string(b), start: start, end: i})
+		case c == '$' && dollarTag(s[i:]) != "":
+			tag := dollarTag(s[i:])
+			j := strings.Index(s[i+len(tag):], tag)
+			if j < 0 {
+				p.failAt(start, "unterminated dollar quoted string")
+			}
+			body := s[i+len(tag) : i+len(tag)+j]
+			i += len(tag) + j + len(tag)
+			tt = append(tt, ddlToken{kind: ddlString, text: body, start: start, end: i})
+		case c == '_' || unicode.IsLetter(rune(c)) || c >= 0x80:
+			for i < len(s) && (s[i] == '_' || s[i] == '$' || s[i] >= '0' && s[i] <= '9' || unicode.IsLetter(rune(s[i])) || s[i] >= 0x80) {
+				i++
+			}
+			tt = append(tt, ddlToken{kind: ddlIdent, text: strings.ToLower(s[start:i]), start: start, end: i})
+		case c >= '0' && c <= '9' || c == '.' && i+1 < len(s) && s[i+1] >= '0' && s[i+1] <= '9':
+			for i < len(s) && (s[i] >= '0' && s[i] <= '9' || s[i] == '.' || s[i] == 'e' || s[i] == 'E') {
+				i++
+			}
+			tt = append(tt, ddlToken{kind: ddlNumber, text: s[start:i], start: start, end: i})
+		case strings.ContainsRune("(),;.[]", rune(c)):
+			i++
+			tt = append(tt, ddlToken{kind: ddlOp, text: s[start:i], start: start, end: i})
+		default:
+			for i < len(s) && strings.ContainsRune("+-*/<>=~!@#%^&|`?:", rune(s[i])) {
+				if i > start && (strings.HasPrefix(s[i:], "--") || strings.HasPrefix(s[i:], "/*")) {
+					break
+				}
+				i++
+			}
+			if i == start {
+				i++
+			}
+			tt = append(tt, ddlToken{kind: ddlOp, text: s[start:i], start: start, end: i})
+		}
+	}
+	return tt
+}
+
+// dollarTag returns the '$tag$' s starts with, or "".
+func dollarTag(s string) string {
+	for i := 1; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '$':
+			return s[:i+1]
+		case c == '_' || unicode.IsLetter(rune(c)) || i > 1 && c >= '0' && c <= '9':
+		default:
+			return ""
+		}
+	}
+	return ""
+}
+
+func (p *ddlParser) peek() ddlToken {
+	if p.pos < len(p.toks) {
+		return p.toks[p.pos]
+	}
+	return ddlToken{kind: ddlOp, text: ";", start: len(p.src), end: len(p.src)}
+}
+
+func (p *ddlParser) next() ddlToken {
+	t := p.peek()
+	if p.pos < len(p.toks) {
+		p.pos++
+	}
+	return t
+}
+
+// is reports whether the next tokens are the keywords or punctuation ww.
+func (p *ddlParser) is(ww ...string) bool {
+	for k, w := range ww {
+		if p.pos+k >= len(p.toks) {
+			return false
+		}
+		t := p.toks[p.pos+k]
+		if t.kind != ddlIdent && t.kind != ddlOp || t.text != w {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *ddlParser) accept(ww ...string) bool {
+	if p.is(ww...) {
+		p.pos += len(ww)
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) expect(ww ...string) {
+	if !p.accept(ww...) {
+		p.fail("expected %s, found %q", strings.Join(ww, " "), p.peek().text)
+	}
+}
+
+func (p *ddlParser) atEnd() bool {
+	return p.pos >= len(p.toks) || p.is(";")
+}
+
+// skipStatement skips to the end of the current statement.
+func (p *ddlParser) skipStatement() {
+	for !p.atEnd() {
+		p.next()
+	}
+	p.accept(";")
+}
+
+// skipUntil skips tokens up to one of stops at the current parenthesis
+// depth, and returns the source text skipped.
+func (p *ddlParser) skipUntil(stops ...string) string {
+	start, end := p.peek().start, p.peek().start
+	depth := 0
+	for !p.atEnd() {
+		t := p.peek()
+		if depth == 0 && (t.kind == ddlIdent || t.kind == ddlOp) {
+			for _, s := range stops {
+				if t.text == s {
+					return p.text(start, end)
+				}
+			}
+		}
+		switch t.text {
+		case "(", "[":
+			depth++
+		case ")", "]":
+			if depth == 0 {
+				return p.text(start, end)
+			}
+			depth--
+		}
+		p.next()
+		end = t.end
+	}
+	return p.text(start, end)
+}
+
+// text returns the source of the tokens between two offsets. Tokens are
+// copied verbatim, so string literals keep their whitespace, and separated
+// by a single space where whitespace or comments separate them.
+func (p *ddlParser) text(start, end int) string {
+	var b bytes.Buffer
+	prev := -1
+	for i := sort.Search(len(p.toks), func(i int) bool { return p.toks[i].start >= start }); i < len(p.toks); i++ {
+		t := p.toks[i]
+		if t.end > end {
+			break
+		}
+		if prev >= 0 && t.start > prev {
+			b.WriteByte(' ')
+		}
+		b.WriteString(p.src[t.start:t.end])
+		prev = t.end
+	}
+	return b.String()
+}
+
+// parenthesized returns the source text inside the parentheses that follow.
+func (p *ddlParser) parenthesized() string {
+	p.expect("(")
+	s := p.skipUntil()
+	p.expect(")")
+	return s
+}
+
+func (p *ddlParser) ident() string {
+	t := p.next()
+	if t.kind != ddlIdent && t.kind != ddlQuoted {
+		p.pos--
+		p.fail("expected a name, found %q", t.text)
+	}
+	return t.text
+}
+
+// qualifiedName reads a name, qualified by the schema of unqualified names
+// when it has none.
+func (p *ddlParser) qualifiedName() (schema, name string) {
+	name = p.ident()
+	if p.accept(".") {
+		return name, p.ident()
+	}
+	return p.schema, name
+}
+
+func (p *ddlParser) identList() []string {
+	p.expect("(")
+	var nn []string
+	for {
+		nn = append(nn, p.ident())
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	return nn
+}
+
+func (p *ddlParser) statement() {
+	switch {
+	case p.accept(";"):
+	case p.accept("create"):
+		p.accept("or", "replace")
+		for p.accept("global") || p.accept("local") || p.accept("temp") || p.accept("temporary") || p.accept("unlogged") {
+		}
+		switch {
+		case p.accept("table"):
+			p.createTable(KindTable)
+		case p.accept("foreign", "table"):
+			p.createTable(KindForeignTable)
+		case p.accept("type"):
+			p.createType()
+		case p.accept("domain"):
+			p.createDomain()
+		case p.accept("function"):
+			p.createFunction(KindFunction)
+		case p.accept("procedure"):
+			p.createFunction(KindProcedure)
+		case p.accept("sequence"):
+			p.createSequence()
+		case p.accept("unique", "index"):
+			p.createIndex(true)
+		case p.accept("index"):
+			p.createIndex(false)
+		default:
+			p.skipStatement()
+		}
+	case p.accept("alter", "table"):
+		p.alterTable()
+	case p.accept("alter", "foreign", "table"):
+		p.alterTable()
+	case p.accept("alter", "type"):
+		p.alterType()
+	case p.accept("alter", "sequence"):
+		p.alterSequence()
+	case p.accept("comment", "on"):
+		p.comment()
+	case p.is("set", "search_path"):
+		p.next()
+		p.next()
+		if p.accept("to") || p.accept("=") {
+			for !p.atEnd() {
+				t := p.next()
+				if t.kind == ddlOp || t.text == "$user" {
+					continue
+				}
+				if s := strings.TrimSpace(strings.Split(t.text, ",")[0]); s != "" && s != "$user" {
+					p.schema = s
+					break
+				}
+			}
+		}
+		p.skipStatement()
+	default:
+		p.skipStatement()
+	}
+}
+
+func (p *ddlParser) createTable(kind string) {
+	p.accept("if", "not", "exists")
+	schema, name := p.qualifiedName()
+	t := &Table{Schema: schema, Name: name, Kind: kind}
+	if p.accept("partition", "of") {
+		ps, pn := p.qualifiedName()
+		t.Inherits = []string{ps + "." + pn}
+		p.tables[t.QualifiedName()] = t
+		// The columns come from the parent; only constraints and column
+		// options can be given.
+		if p.accept("(") {
+			for !p.accept(")") {
+				if p.is("constraint") || p.is("primary") || p.is("unique") || p.is("foreign") || p.is("check") || p.is("exclude") {
+					p.tableConstraint(t)
+				} else {
+					p.skipUntil(",")
+				}
+				if !p.accept(",") {
+					p.expect(")")
+					break
+				}
+			}
+		}
+		t.PartitionBound = p.partitionBound()
+		p.tableOptions(t)
+		return
+	}
+	if !p.is("(") {
+		// CREATE TABLE ... AS or OF type.
+		p.skipStatement()
+		return
+	}
+	p.tables[t.QualifiedName()] = t
+	p.expect("(")
+	for !p.accept(")") {
+		p.tableElement(t)
+		if !p.accept(",") {
+			p.expect(")")
+			break
+		}
+	}
+	p.tableOptions(t)
+}
+
+// tableOptions reads the INHERITS and PARTITION BY clauses following the
+// columns in CREATE TABLE, and skips the rest of the statement.
+func (p *ddlParser) tableOptions(t *Table) {
+	for !p.atEnd() {
+		switch {
+		case p.accept("inherits"):
+			p.expect("(")
+			for {
+				s, n := p.qualifiedName()
+				t.Inherits = append(t.Inherits, s+"."+n)
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect(")")
+		case p.accept("partition", "by"):
+			method := strings.ToUpper(p.ident())
+			t.PartitionKey = method + " (" + p.parenthesized() + ")"
+		default:
+			p.next()
+		}
+	}
+	p.accept(";")
+}
+
+// partitionBound reads 'FOR VALUES ...' or 'DEFAULT', and returns it as
+// written.
+func (p *ddlParser) partitionBound() string {
+	if p.accept("default") {
+		return "DEFAULT"
+	}
+	start := p.peek().start
+	p.expect("for", "values")
+	switch {
+	case p.accept("from"):
+		p.parenthesized()
+		p.expect("to")
+		p.parenthesized()
+	case p.accept("in"), p.accept("with"):
+		p.parenthesized()
+	default:
+		p.fail("expected FROM, IN or WITH, found %q", p.peek().text)
+	}
+	return p.text(start, p.toks[p.pos-1].end)
+}
+
+// tableElement reads a column or table constraint in CREATE TABLE.
+func (p *ddlParser) tableElement(t *Table) {
+	switch {
+	case p.is("constraint"), p.is("primary"), p.is("unique"), p.is("foreign"), p.is("check"), p.is("exclude"):
+		p.tableConstraint(t)
+	case p.is("like"):
+		p.skipUntil(",")
+	default:
+		p.column(t)
+	}
+}
+
+func (p *ddlParser) column(t *Table) {
+	c := &Column{Name: p.ident(), Nullable: true, Position: len(t.Columns) + 1}
+	var serial bool
+	c.TypeSchema, c.DataType, serial = p.dataType()
+	p.setModifier(c)
+	if serial {
+		c.Nullable = false
+		c.Default = fmt.Sprintf("nextval('%s'::regclass)", sequenceName(t, c))
+		p.ownedSequence(t, c, t.Schema, t.Name+"_"+c.Name+"_seq", c.DataType)
+	}
+	t.Columns = append(t.Columns, c)
+	p.columnConstraints(t, c)
+}
+
+// setModifier sets the precision and scale of a numeric column from the
+// modifier of the type just read.
+func (p *ddlParser) setModifier(c *Column) {
+	c.Precision, c.Scale = 0, 0
+	if c.DataType != "numeric" || p.typmod == "" {
+		return
+	}
+	parts := strings.Split(p.typmod, ",")
+	c.Precision, _ = strconv.Atoi(strings.TrimSpace(parts[0]))
+	if len(parts) > 1 {
+		c.Scale, _ = strconv.Atoi(strings.TrimSpace(parts[1]))
+	}
+}
+
+func sequenceName(t *Table, c *Column) string {
+	n := t.Name + "_" + c.Name + "_seq"
+	if t.Schema != "public" {
+		n = t.Schema + "." + n
+	}
+	return n
+}
+
+// columnConstraints reads the constraints following a column definition.
+func (p *ddlParser) columnConstraints(t *Table, c *Column) {
+	for !p.atEnd() && !p.is(",") && !p.is(")") {
+		var name string
+		if p.accept("constraint") {
+			name = p.ident()
+		}
+		switch {
+		case p.accept("not", "null"):
+			c.Nullable = false
+		case p.accept("null"):
+		case p.accept("default"):
+			c.Default = p.skipUntil(",", "constraint", "not", "null", "primary", "unique", "references", "check", "generated", "collate")
+		case p.accept("collate"):
+			p.qualifiedName()
+		case p.accept("primary", "key"):
+			c.Nullable = false
+			p.addIndex(t, &Index{Name: orDefault(name, t.Name+"_pkey"), Method: "btree", IsUnique: true, IsPrimary: true}, []string{c.Name})
+		case p.accept("unique"):
+			p.addIndex(t, &Index{Name: orDefault(name, t.Name+"_"+c.Name+"_key"), Method: "btree", IsUnique: true}, []string{c.Name})
+		case p.is("references"):
+			p.references(t, orDefault(name, t.Name+"_"+c.Name+"_fkey"), []string{c.Name})
+		case p.accept("check"):
+			t.Checks = append(t.Checks, &Check{Name: orDefault(name, t.Name+"_"+c.Name+"_check"), Definition: "CHECK (" + p.parenthesized() + ")"})
+			p.accept("no", "inherit")
+		case p.accept("generated"):
+			switch {
+			case p.accept("always", "as", "identity"):
+				c.Identity, c.Nullable = "ALWAYS", false
+				p.identitySequence(t, c)
+			case p.accept("by", "default", "as", "identity"):
+				c.Identity, c.Nullable = "BY DEFAULT", false
+				p.identitySequence(t, c)
+			case p.accept("always", "as"):
+				c.Generated = p.parenthesized()
+				p.accept("stored")
+			default:
+				p.fail("unsupported generated column")
+			}
+		case p.accept("deferrable"), p.accept("not", "deferrable"), p.accept("initially", "deferred"), p.accept("initially", "immediate"):
+		default:
+			p.fail("unsupported column constraint %q", p.peek().text)
+		}
+	}
+}
+
+// identitySequence reads the sequence options of an identity column, and
+// adds its sequence.
+func (p *ddlParser) identitySequence(t *Table, c *Column) {
+	schema, name, typ := t.Schema, t.Name+"_"+c.Name+"_seq", c.DataType
+	if p.accept("(") {
+		for !p.atEnd() && !p.accept(")") {
+			switch {
+			case p.accept("sequence", "name"):
+				schema, name = p.qualifiedName()
+			case p.accept("as"):
+				_, typ, _ = p.dataType()
+			default:
+				p.next()
+			}
+		}
+	}
+	p.ownedSequence(t, c, schema, name, typ)
+}
+
+// ownedSequence adds the sequence created for a serial or identity column.
+func (p *ddlParser) ownedSequence(t *Table, c *Column, schema, name, typ string) {
+	sq := &Sequence{Schema: schema, Name: name, DataType: typ, OwnerTable: t.QualifiedName(), OwnerColumn: c.Name}
+	p.sequences[sq.QualifiedName()] = sq
+}
+
+func orDefault(s, def string) string {
+	if s != "" {
+		return s
+	}
+	return def
+}
+
+// tableConstraint reads a table constraint, in CREATE TABLE or
+// ALTER TABLE ... ADD.
+func (p *ddlParser) tableConstraint(t *Table) {
+	var name string
+	if p.accept("constraint") {
+		name = p.ident()
+	}
+	switch {
+	case p.accept("primary", "key"):
+		cols := p.identList()
+		p.addIndex(t, &Index{Name: orDefault(name, t.Name+"_pkey"), Method: "btree", IsUnique: true, IsPrimary: true}, cols)
+	case p.accept("unique"):
+		cols := p.identList()
+		p.addIndex(t, &Index{Name: orDefault(name, t.Name+"_"+strings.Join(cols, "_")+"_key"), Method: "btree", IsUnique: true}, cols)
+	case p.accept("foreign", "key"):
+		cols := p.identList()
+		p.references(t, orDefault(name, t.Name+"_"+strings.Join(cols, "_")+"_fkey"), cols)
+	case p.accept("check"):
+		def := "CHECK (" + p.parenthesized() + ")"
+		p.accept("no", "inherit")
+		if p.accept("not", "valid") {
+			def += " NOT VALID"
+		}
+		t.Checks = append(t.Checks, &Check{Name: orDefault(name, t.Name+"_check"), Definition: def})
+	case p.accept("exclude"):
+	default:
+		p.fail("unsupported table constraint %q", p.peek().text)
+	}
+	p.skipUntil(",")
+}
+
+func (p *ddlParser) addIndex(t *Table, ix *Index, keys []string) {
+	ix.Keys = keys
+	p.indexes = append(p.indexes, ddlIndex{table: t, index: ix, keys: keys})
+}
+
+// references reads 'REFERENCES table [(cols)] [ON DELETE action] ...'.
+func (p *ddlParser) references(t *Table, name string, cols []string) {
+	p.expect("references")
+	fk := &ForeignKey{Name: name, OnDelete: "NO ACTION", OnUpdate: "NO ACTION"}
+	fk.RefSchema, fk.RefTable = p.qualifiedName()
+	if p.is("(") {
+		fk.RefColumns = p.identList()
+	}
+	for {
+		switch {
+		case p.accept("on", "delete"):
+			fk.OnDelete = p.refAction()
+		case p.accept("on", "update"):
+			fk.OnUpdate = p.refAction()
+		case p.accept("match", "full"), p.accept("match", "simple"), p.accept("match", "partial"):
+		case p.accept("deferrable"), p.accept("not", "deferrable"), p.accept("initially", "deferred"), p.accept("initially", "immediate"), p.accept("not", "valid"):
+		default:
+			p.foreignKeys = append(p.foreignKeys, ddlForeignKey{table: t, fk: fk, cols: cols})
+			return
+		}
+	}
+}
+
+func (p *ddlParser) refAction() string {
+	for _, a := range []string{"no action", "restrict", "cascade", "set null", "set default"} {
+		if p.accept(strings.Fields(a)...) {
+			if p.is("(") {
+				// SET NULL (cols)
+				p.parenthesized()
+			}
+			return strings.ToUpper(a)
+		}
+	}
+	p.fail("unsupported referential action %q", p.peek().text)
+	return ""
+}
+
+// ddlTypes maps the names and aliases of built in types to the names the
+// catalog uses. Serial types map to their integer type.
+var ddlTypes = map[string]string{
+	"int":                         "int4",
+	"integer":                     "int4",
+	"int4":                        "int4",
+	"serial":                      "int4",
+	"serial4":                     "int4",
+	"smallint":                    "int2",
+	"int2":                        "int2",
+	"smallserial":                 "int2",
+	"serial2":                     "int2",
+	"bigint":                      "int8",
+	"int8":                        "int8",
+	"bigserial":                   "int8",
+	"serial8":                     "int8",
+	"real":                        "float4",
+	"float4":                      "float4",
+	"double precision":            "float8",
+	"float8":                      "float8",
+	"float":                       "float8",
+	"numeric":                     "numeric",
+	"decimal":                     "numeric",
+	"boolean":                     "bool",
+	"bool":                        "bool",
+	"text":                        "text",
+	"character varying":           "varchar",
+	"varchar":                     "varchar",
+	"character":                   "bpchar",
+	"char":                        "bpchar",
+	"bpchar":                      "bpchar",
+	"bytea":                       "bytea",
+	"timestamp":                   "timestamp",
+	"timestamp without time zone": "timestamp",
+	"timestamp with time zone":    "timestamptz",
+	"timestamptz":                 "timestamptz",
+	"time":                        "time",
+	"time without time zone":      "time",
+	"time with time zone":         "timetz",
+	"timetz":                      "timetz",
+	"date":                        "date",
+	"interval":                    "interval",
+	"uuid":                        "uuid",
+	"json":                        "json",
+	"jsonb":                       "jsonb",
+	"inet":                        "inet",
+	"cidr":                        "cidr",
+	"macaddr":                     "macaddr",
+	"macaddr8":                    "macaddr8",
+	"money":                       "money",
+	"xml":                         "xml",
+	"oid":                         "oid",
+	"bit":                         "bit",
+	"bit varying":                 "varbit",
+	"varbit":                      "varbit",
+	"tsvector":                    "tsvector",
+	"tsquery":                     "tsquery",
+	"point":                       "point",
+	"line":                        "line",
+	"lseg":                        "lseg",
+	"box":                         "box",
+	"path":                        "path",
+	"polygon":                     "polygon",
+	"circle":                      "circle",
+	"int4range":                   "int4range",
+	"int8range":                   "int8range",
+	"numrange":                    "numrange",
+	"tsrange":                     "tsrange",
+	"tstzrange":                   "tstzrange",
+	"daterange":                   "daterange",
+	"name":                        "name",
+	"regclass":                    "regclass",
+	// Pseudo-types, which only functions take or return.
+	"void":          "void",
+	"record":        "record",
+	"trigger":       "trigger",
+	"event_trigger": "event_trigger",
+	"anyelement":    "anyelement",
+	"anyarray":      "anyarray",
+	"anynonarray":   "anynonarray",
+	"anyenum":       "anyenum",
+	"anyrange":      "anyrange",
+	"internal":      "internal",
+	"cstring":       "cstring",
+}
+
+// dataType reads a type name, with its modifiers and array bounds, and
+// returns its catalog name and schema.
+func (p *ddlParser) dataType() (schema, name string, serial bool) {
+	p.typmod = ""
+	t := p.peek()
+	first := p.ident()
+	if t.kind == ddlIdent {
+		// Multi word names: double precision, character varying,
+		// timestamp with time zone, ...
+		words := []string{first}
+		if p.accept("precision") {
+			words = append(words, "precision")
+		} else if p.accept("varying") {
+			words = append(words, "varying")
+		}
+		if first == "timestamp" || first == "time" {
+			if p.is("(") {
+				p.parenthesized()
+			}
+			if p.accept("with", "time", "zone") {
+				words = append(words, "with time zone")
+			} else if p.accept("without", "time", "zone") {
+				words = append(words, "without time zone")
+			}
+		}
+		if first == "interval" {
+			for p.peek().kind == ddlIdent && isIntervalField(p.peek().text) {
+				p.next()
+			}
+		}
+		n := strings.Join(words, " ")
+		if catalog, ok := ddlTypes[n]; ok && !p.is(".") {
+			name, schema = catalog, "pg_catalog"
+			serial = strings.Contains(n, "serial")
+			if n == "float" && p.is("(") {
+				if prec, err := strconv.Atoi(strings.TrimSpace(p.parenthesized())); err == nil && prec <= 24 {
+					name = "float4"
+				}
+			}
+		}
+	}
+	if name == "" {
+		schema, name = p.schema, first
+		if p.accept(".") {
+			schema, name = first, p.ident()
+		}
+		if schema == "pg_catalog" {
+			if catalog, ok := ddlTypes[name]; ok {
+				name = catalog
+			}
+		}
+	}
+	if p.is("(") {
+		p.typmod = p.parenthesized()
+	}
+	array := false
+	for {
+		switch {
+		case p.accept("["):
+			p.skipUntil("]")
+			p.expect("]")
+			array = true
+			continue
+		case p.accept("array"):
+			if p.accept("[") {
+				p.skipUntil("]")
+				p.expect("]")
+			}
+			array = true
+			continue
+		}
+		break
+	}
+	if array {
+		name = "_" + name
+	}
+	return schema, name, serial
+}
+
+func isIntervalField(s string) bool {
+	switch s {
+	case "year", "month", "day", "hour", "minute", "second", "to":
+		return true
+	}
+	return false
+}
+
+func (p *ddlParser) createType() {
+	schema, name := p.qualifiedName()
+	if !p.accept("as") {
+		p.skipStatement()
+		return
+	}
+	switch {
+	case p.accept("enum"):
+		en := &Enum{Schema: schema, Name: name, Values: []*EnumValue{}}
+		p.expect("(")
+		for !p.accept(")") {
+			t := p.next()
+			if t.kind != ddlString {
+				p.fail("expected an enum label")
+			}
+			en.Values = append(en.Values, &EnumValue{Value: t.text})
+			p.accept(",")
+		}
+		p.enums[en.QualifiedName()] = en
+	case p.is("("):
+		c := &Composite{Schema: schema, Name: name}
+		p.expect("(")
+		for !p.accept(")") {
+			attr := &Column{Name: p.ident(), Nullable: true, Position: len(c.Attributes) + 1}
+			attr.TypeSchema, attr.DataType, _ = p.dataType()
+			if p.accept("collate") {
+				p.qualifiedName()
+			}
+			c.Attributes = append(c.Attributes, attr)
+			p.accept(",")
+		}
+		p.composites[c.QualifiedName()] = c
+	}
+	p.skipStatement()
+}
+
+func (p *ddlParser) createDomain() {
+	d := &Domain{}
+	d.Schema, d.Name = p.qualifiedName()
+	p.accept("as")
+	d.BaseTypeSchema, d.BaseType, _ = p.dataType()
+	for !p.atEnd() {
+		if p.accept("constraint") {
+			// Domain constraint names aren't kept.
+			p.ident()
+		}
+		switch {
+		case p.accept("collate"):
+			p.qualifiedName()
+		case p.accept("default"):
+			d.Default = p.skipUntil("constraint", "not", "null", "check", "collate")
+		case p.accept("not", "null"):
+			d.NotNull = true
+		case p.accept("null"):
+		case p.accept("check"):
+			d.Checks = append(d.Checks, "CHECK ("+p.parenthesized()+")")
+		default:
+			p.fail("unsupported domain constraint %q", p.peek().text)
+		}
+	}
+	p.domains[d.QualifiedName()] = d
+	p.skipStatement()
+}
+
+func (p *ddlParser) createFunction(kind string) {
+	f := &Function{Kind: kind}
+	f.Schema, f.Name = p.qualifiedName()
+	names, modes, types, typeSchemas := p.routineArgs()
+	var retSchema, retType string
+	if p.accept("returns") {
+		switch {
+		case p.accept("table"):
+			f.ReturnsSet = true
+			p.expect("(")
+			for {
+				names = append(names, p.ident())
+				modes = append(modes, "t")
+				schema, typ, _ := p.dataType()
+				types, typeSchemas = append(types, typ), append(typeSchemas, schema)
+				if !p.accept(",") {
+					break
+				}
+			}
+			p.expect(")")
+		case p.accept("setof"):
+			f.ReturnsSet = true
+			fallthrough
+		default:
+			retSchema, retType, _ = p.dataType()
+		}
+	}
+	if f.addArgs(names, modes, types, typeSchemas) {
+		if len(f.Results) == 0 && retType != "" && retType != "void" && !f.IsProcedure() {
+			f.Results = []*Column{{Position: 1, Nullable: true, DataType: retType, TypeSchema: retSchema}}
+		}
+		p.functions[f.Signature()] = f
+	}
+	p.skipRoutineBody()
+}
+
+// routineArgs reads the parenthesized arguments of a function, with their
+// names and modes as pg_proc has them: 'i', 'o', 'b' for INOUT and 'v' for
+// VARIADIC. Unnamed arguments have empty names.
+func (p *ddlParser) routineArgs() (names, modes, types, typeSchemas []string) {
+	p.expect("(")
+	for !p.accept(")") {
+		mode := "i"
+		switch {
+		case p.accept("in"):
+		case p.accept("out"):
+			mode = "o"
+		case p.accept("inout"):
+			mode = "b"
+		case p.accept("variadic"):
+			mode = "v"
+		}
+		// The name is optional, and told apart from a type by what
+		// follows: a type name may be several words.
+		start, name := p.pos, ""
+		schema, typ, _ := p.dataType()
+		if !p.is(",") && !p.is(")") && !p.is("default") && !p.is("=") {
+			p.pos = start
+			name = p.ident()
+			schema, typ, _ = p.dataType()
+		}
+		if p.accept("default") || p.accept("=") {
+			p.skipUntil(",")
+		}
+		names, modes = append(names, name), append(modes, mode)
+		types, typeSchemas = append(types, typ), append(typeSchemas, schema)
+		p.accept(",")
+	}
+	return names, modes, types, typeSchemas
+}
+
+// skipRoutineBody skips the rest of CREATE FUNCTION, including a BEGIN
+// ATOMIC body whose statements end with semicolons of their own.
+func (p *ddlParser) skipRoutineBody() {
+	for !p.atEnd() {
+		if !p.accept("begin", "atomic") {
+			p.next()
+			continue
+		}
+		for depth := 0; p.pos < len(p.toks); {
+			t := p.next()
+			if t.kind != ddlIdent {
+				continue
+			}
+			if t.text == "case" {
+				depth++
+			} else if t.text == "end" {
+				if depth == 0 {
+					break
+				}
+				depth--
+			}
+		}
+	}
+	p.accept(";")
+}
+
+func (p *ddlParser) createSequence() {
+	p.accept("if", "not", "exists")
+	sq := &Sequence{DataType: "int8"}
+	sq.Schema, sq.Name = p.qualifiedName()
+	p.sequences[sq.QualifiedName()] = sq
+	p.sequenceOptions(sq)
+}
+
+func (p *ddlParser) alterSequence() {
+	p.accept("if", "exists")
+	schema, name := p.qualifiedName()
+	sq := p.sequences[schema+"."+name]
+	if sq == nil {
+		p.skipStatement()
+		return
+	}
+	p.sequenceOptions(sq)
+}
+
+// sequenceOptions reads the type and owner of a sequence, skipping its other
+// options.
+func (p *ddlParser) sequenceOptions(sq *Sequence) {
+	for !p.atEnd() {
+		switch {
+		case p.accept("as"):
+			_, sq.DataType, _ = p.dataType()
+		case p.accept("owned", "by", "none"):
+			sq.OwnerTable, sq.OwnerColumn = "", ""
+		case p.accept("owned", "by"):
+			parts := []string{p.ident()}
+			for p.accept(".") {
+				parts = append(parts, p.ident())
+			}
+			if len(parts) == 2 {
+				parts = append([]string{p.schema}, parts...)
+			}
+			if len(parts) != 3 {
+				p.fail("expected a column")
+			}
+			sq.OwnerTable, sq.OwnerColumn = parts[0]+"."+parts[1], parts[2]
+		default:
+			p.next()
+		}
+	}
+	p.skipStatement()
+}
+
+func (p *ddlParser) createIndex(unique bool) {
+	p.accept("concurrently")
+	p.accept("if", "not", "exists")
+	var name string
+	if !p.is("on") {
+		name = p.ident()
+	}
+	p.expect("on")
+	p.accept("only")
+	schema, table := p.qualifiedName()
+	t := p.tables[schema+"."+table]
+	ix := &Index{Name: name, Method: "btree", IsUnique: unique}
+	if p.accept("using") {
+		ix.Method = p.ident()
+	}
+	p.expect("(")
+	var cols []string
+	for {
+		start := p.peek().start
+		col := ""
+		if k := p.peek(); (k.kind == ddlIdent || k.kind == ddlQuoted) && !p.isAt(1, "(") && !p.isAt(1, ".") {
+			col = k.text
+		}
+		p.skipUntil(",", "collate", "asc", "desc", "nulls")
+		key := p.text(start, p.toks[p.pos-1].end)
+		if col != "" {
+			key = col
+		} else if strings.HasPrefix(key, "(") && strings.HasSuffix(key, ")") {
+			key = key[1 : len(key)-1]
+		}
+		// Operator classes, sort orders and null ordering aren't kept.
+		p.skipUntil(",")
+		ix.Keys = append(ix.Keys, key)
+		cols = append(cols, col)
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.expect(")")
+	if p.accept("include") {
+		p.parenthesized()
+	}
+	if p.accept("with") {
+		p.parenthesized()
+	}
+	if p.accept("tablespace") {
+		p.ident()
+	}
+	if p.accept("where") {
+		ix.Predicate = p.skipUntil()
+	}
+	p.skipStatement()
+	if t == nil {
+		return
+	}
+	if ix.Name == "" {
+		ix.Name = t.Name + "_" + strings.Join(cols, "_") + "_idx"
+	}
+	p.indexes = append(p.indexes, ddlIndex{table: t, index: ix, keys: cols})
+}
+
+func (p *ddlParser) isAt(offset int, text string) bool {
+	i := p.pos + offset
+	return i < len(p.toks) && p.toks[i].kind == ddlOp && p.toks[i].text == text
+}
+
+func (p *ddlParser) alterTable() {
+	p.accept("if", "exists")
+	p.accept("only")
+	schema, name := p.qualifiedName()
+	p.accept("*")
+	t := p.tables[schema+"."+name]
+	if t == nil {
+		p.skipStatement()
+		return
+	}
+	for {
+		switch {
+		case p.accept("add"):
+			if p.is("constraint") || p.is("primary") || p.is("unique") || p.is("foreign") || p.is("check") || p.is("exclude") {
+				p.tableConstraint(t)
+				break
+			}
+			p.accept("column")
+			if p.accept("if", "not", "exists") && t.Column(p.peek().text) != nil {
+				p.skipUntil(",")
+				break
+			}
+			p.column(t)
+		case p.accept("attach", "partition"):
+			cs, cn := p.qualifiedName()
+			bound := p.partitionBound()
+			if c := p.tables[cs+"."+cn]; c != nil {
+				c.Inherits, c.PartitionBound = []string{t.QualifiedName()}, bound
+			}
+			p.skipUntil(",")
+		case p.accept("detach", "partition"):
+			cs, cn := p.qualifiedName()
+			if c := p.tables[cs+"."+cn]; c != nil {
+				// The partition keeps the columns and checks it had.
+				p.inherit(c, map[*Table]bool{})
+				c.Inherits, c.PartitionBound = nil, ""
+			}
+			p.skipUntil(",")
+		case p.accept("inherit"):
+			ps, pn := p.qualifiedName()
+			t.Inherits = append(t.Inherits, ps+"."+pn)
+		case p.accept("no", "inherit"):
+			ps, pn := p.qualifiedName()
+			p.inherit(t, map[*Table]bool{})
+			for k, n := range t.Inherits {
+				if n == ps+"."+pn {
+					t.Inherits = append(t.Inherits[:k], t.Inherits[k+1:]...)
+					break
+				}
+			}
+		case p.accept("drop", "constraint"):
+			p.accept("if", "exists")
+			p.dropConstraint(t, p.ident())
+			p.skipUntil(",")
+		case p.accept("drop"):
+			p.accept("column")
+			p.accept("if", "exists")
+			p.dropColumn(t, p.ident())
+			p.skipUntil(",")
+		case p.accept("alter"):
+			p.accept("column")
+			c := t.Column(p.ident())
+			if c == nil {
+				p.fail("unknown column")
+			}
+			p.alterColumn(t, c)
+		default:
+			p.skipUntil(",")
+		}
+		if !p.accept(",") {
+			break
+		}
+	}
+	p.skipStatement()
+}
+
+func (p *ddlParser) dropColumn(t *Table, name string) {
+	for k, c := range t.Columns {
+		if c.Name == name {
+			t.Columns = append(t.Columns[:k], t.Columns[k+1:]...)
+			return
+		}
+	}
+}
+
+func (p *ddlParser) dropConstraint(t *Table, name string) {
+	for k, ix := range p.indexes {
+		if ix.table == t && ix.index.Name == name {
+			p.indexes = append(p.indexes[:k], p.indexes[k+1:]...)
+			return
+		}
+	}
+	for k, f := range p.foreignKeys {
+		if f.table == t && f.fk.Name == name {
+			p.foreignKeys = append(p.foreignKeys[:k], p.foreignKeys[k+1:]...)
+			return
+		}
+	}
+	for k, ck := range t.Checks {
+		if ck.Name == name {
+			t.Checks = append(t.Checks[:k], t.Checks[k+1:]...)
+			return
+		}
+	}
+}
+
+func (p *ddlParser) alterColumn(t *Table, c *Column) {
+	switch {
+	case p.accept("set", "default"):
+		c.Default = p.skipUntil(",")
+	case p.accept("drop", "default"):
+		c.Default = ""
+	case p.accept("set", "not", "null"):
+		c.Nullable = false
+	case p.accept("drop", "not", "null"):
+		c.Nullable = true
+	case p.accept("add", "generated", "always", "as", "identity"):
+		c.Identity, c.Nullable = "ALWAYS", false
+		p.identitySequence(t, c)
+	case p.accept("add", "generated", "by", "default", "as", "identity"):
+		c.Identity, c.Nullable = "BY DEFAULT", false
+		p.identitySequence(t, c)
+	case p.accept("drop", "identity"):
+		c.Identity = ""
+		for k, sq := range p.sequences {
+			if sq.OwnerTable == t.QualifiedName() && sq.OwnerColumn == c.Name {
+				delete(p.sequences, k)
+			}
+		}
+	case p.accept("set", "data", "type"), p.accept("type"):
+		c.TypeSchema, c.DataType, _ = p.dataType()
+		p.setModifier(c)
+	}
+	p.skipUntil(",")
+}
+
+func (p *ddlParser) alterType() {
+	schema, name := p.qualifiedName()
+	en := p.enums[schema+"."+name]
+	if en == nil || !p.accept("add", "value") {
+		p.skipStatement()
+		return
+	}
+	if p.accept("if", "not", "exists") {
+		for _, v := range en.Values {
+			if v.Value == p.peek().text {
+				p.skipStatement()
+				return
+			}
+		}
+	}
+	v := &EnumValue{Value: p.next().text}
+	at := len(en.Values)
+	before := p.accept("before")
+	if before || p.accept("after") {
+		ref := p.next().text
+		for k, ev := range en.Values {
+			if ev.Value == ref {
+				at = k
+				if !before {
+					at++
+				}
+			}
+		}
+	}
+	en.Values = append(en.Values[:at], append([]*EnumValue{v}, en.Values[at:]...)...)
+	p.skipStatement()
+}
+
+func (p *ddlParser) comment() {
+	var t *Table
+	var c *Column
+	var f *Function
+	var sq *Sequence
+	switch {
+	case p.accept("sequence"):
+		schema, name := p.qualifiedName()
+		sq = p.sequences[schema+"."+name]
+	case p.accept("function"), p.accept("procedure"):
+		sig := &Function{}
+		sig.Schema, sig.Name = p.qualifiedName()
+		sig.addArgs(p.routineArgs())
+		f = p.functions[sig.Signature()]
+	case p.accept("table"), p.accept("foreign", "table"):
+		schema, name := p.qualifiedName()
+		t = p.tables[schema+"."+name]
+	case p.accept("column"):
+		parts := []string{p.ident()}
+		for p.accept(".") {
+			parts = append(parts, p.ident())
+		}
+		if len(parts) == 2 {
+			parts = append([]string{p.schema}, parts...)
+		}
+		if len(parts) == 3 {
+			if tbl := p.tables[parts[0]+"."+parts[1]]; tbl != nil {
+				c = tbl.Column(parts[2])
+			}
+		}
+	default:
+		p.skipStatement()
+		return
+	}
+	p.expect("is")
+	var text string
+	if tok := p.next(); tok.kind == ddlString {
+		text = tok.text
+	}
+	switch {
+	case t != nil:
+		t.Comment = text
+	case c != nil:
+		c.Comment = text
+	case f != nil:
+		f.Comment = text
+	case sq != nil:
+		sq.Comment = text
+	}
+	p.skipStatement()
+}
+
+// finish resolves the columns of keys and the base types of domains, and
+// returns the objects in schemas.
+// inherit puts the columns of the parents of t ahead of its own, as the
+// server does, merging those t declares again, and copies their checks.
+// Parents are done first.
+func (p *ddlParser) inherit(t *Table, done map[*Table]bool) {
+	if done[t] {
+		return
+	}
+	done[t] = true
+	var cols []*Column
+	seen := map[string]bool{}
+	for _, n := range t.Inherits {
+		parent := p.tables[n]
+		if parent == nil {
+			continue
+		}
+		p.inherit(parent, done)
+		for _, pc := range parent.Columns {
+			if seen[pc.Name] {
+				continue
+			}
+			seen[pc.Name] = true
+			c := t.Column(pc.Name)
+			if c == nil {
+				cc := *pc
+				// Comments aren't inherited.
+				cc.Comment = ""
+				c = &cc
+			}
+			c.Nullable = c.Nullable && pc.Nullable
+			cols = append(cols, c)
+		}
+		for _, ck := range parent.Checks {
+			found := false
+			for _, own := range t.Checks {
+				found = found || own.Name == ck.Name
+			}
+			if !found {
+				cc := *ck
+				t.Checks = append(t.Checks, &cc)
+			}
+		}
+	}
+	if len(cols) == 0 {
+		return
+	}
+	for _, c := range t.Columns {
+		if !seen[c.Name] {
+			cols = append(cols, c)
+		}
+	}
+	for k, c := range cols {
+		c.Position = k + 1
+	}
+	t.Columns = cols
+}
+
+func (p *ddlParser) finish(schemas []string) (*PGData, error) {
+	inSchemas := func(s string) bool {
+		if len(schemas) == 0 {
+			return true
+		}
+		for _, sch := range schemas {
+			if sch == s {
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, d := range p.domains {
+		seen := map[*Domain]bool{}
+		for base := p.domains[d.BaseTypeSchema+"."+d.BaseType]; base != nil && !seen[base]; base = p.domains[d.BaseTypeSchema+"."+d.BaseType] {
+			seen[base] = true
+			d.BaseType, d.BaseTypeSchema = base.BaseType, base.BaseTypeSchema
+		}
+	}
+
+	done := map[*Table]bool{}
+	for _, t := range p.tables {
+		p.inherit(t, done)
+	}
+
+	for _, ix := range p.indexes {
+		ix.index.Columns = nil
+		for _, k := range ix.keys {
+			c := ix.table.Column(k)
+			if c != nil && ix.index.IsPrimary {
+				// Primary key columns are implicitly NOT NULL.
+				c.Nullable = false
+			}
+			ix.index.Columns = append(ix.index.Columns, c)
+		}
+		ix.table.Indexes = append(ix.table.Indexes, ix.index)
+	}
+	for _, t := range p.tables {
+		setPrimaryKeys(t)
+	}
+	for _, f := range p.foreignKeys {
+		for _, n := range f.cols {
+			c := f.table.Column(n)
+			if c == nil {
+				return nil, errors.Errorf("foreign key %s: unknown column %s.%s", f.fk.Name, f.table.Name, n)
+			}
+			f.fk.Columns = append(f.fk.Columns, c)
+		}
+		if f.fk.RefColumns == nil {
+			ref := p.tables[f.fk.RefSchema+"."+f.fk.RefTable]
+			if ref == nil {
+				return nil, errors.Errorf("foreign key %s: unknown table %s.%s", f.fk.Name, f.fk.RefSchema, f.fk.RefTable)
+			}
+			for _, pk := range ref.PrimaryKeys {
+				f.fk.RefColumns = append(f.fk.RefColumns, pk.Name)
+			}
+		}
+		f.table.ForeignKeys = append(f.table.ForeignKeys, f.fk)
+	}
+
+	data := &PGData{
+		Enums:      map[string]*Enum{},
+		Composites: map[string]*Composite{},
+		Domains:    map[string]*Domain{},
+		Tables:     map[string]*Table{},
+	}
+	for k, en := range p.enums {
+		if inSchemas(en.Schema) {
+			data.Enums[k] = en
+			registerEnum(en)
+		}
+	}
+	for k, d := range p.domains {
+		if inSchemas(d.Schema) {
+			data.Domains[k] = d
+		}
+	}
+	for k, c := range p.composites {
+		if inSchemas(c.Schema) {
+			data.Composites[k] = c
+			resolveDomains(c.Attributes, p.domains)
+			registerComposite(c)
+		}
+	}
+	for k, t := range p.tables {
+		if !inSchemas(t.Schema) {
+			continue
+		}
+		data.Tables[k] = t
+		resolveDomains(t.Columns, p.domains)
+		sort.Slice(t.Indexes, func(i, j int) bool { return t.Indexes[i].Name < t.Indexes[j].Name })
+		sort.Slice(t.ForeignKeys, func(i, j int) bool { return t.ForeignKeys[i].Name < t.ForeignKeys[j].Name })
+		sort.Slice(t.Checks, func(i, j int) bool { return t.Checks[i].Name < t.Checks[j].Name })
+	}
+	for k, f := range p.functions {
+		if !inSchemas(f.Schema) {
+			continue
+		}
+		if data.Functions == nil {
+			data.Functions = map[string]*Function{}
+		}
+		data.Functions[k] = f
+		resolveDomains(f.Args, p.domains)
+		resolveDomains(f.Results, p.domains)
+	}
+	for k, sq := range p.sequences {
+		if !inSchemas(sq.Schema) {
+			continue
+		}
+		if sq.IsOwned() && sq.owner(&PGData{Tables: p.tables}) == nil {
+			// Dropped along with its column.
+			continue
+		}
+		if data.Sequences == nil {
+			data.Sequences = map[string]*Sequence{}
+		}
+		data.Sequences[k] = sq
+	}
+	return data, nil
+}
@@ -0,0 +1,294 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDDL(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		check func(t *testing.T, d *PGData)
+	}{
+		{
+			name: "pg_dump constraints",
+			src: `
+CREATE TABLE public.customers (
+    id integer NOT NULL,
+    name character varying(80) NOT NULL
+);
+CREATE TABLE public.orders (
+    id bigint NOT NULL,
+    customer_id integer
+);
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_pkey PRIMARY KEY (id);
+ALTER TABLE ONLY public.customers
+    ADD CONSTRAINT customers_name_key UNIQUE (name);
+ALTER TABLE ONLY public.orders
+    ADD CONSTRAINT orders_customer_id_fkey FOREIGN KEY (customer_id) REFERENCES public.customers(id) ON DELETE CASCADE;
+ALTER TABLE public.orders
+    ADD CONSTRAINT orders_customer_id_check CHECK ((customer_id > 0)) NOT VALID;
+`,
+			check: func(t *testing.T, d *PGData) {
+				c, o := table(t, d, "public.customers"), table(t, d, "public.orders")
+				if len(c.PrimaryKeys) != 1 || c.PrimaryKeys[0] != c.Column("id") || !c.Column("id").IsPK {
+					t.Errorf("customers primary key = %v, want id", c.PrimaryKeys)
+				}
+				if c.Column("name").DataType != "varchar" || c.Column("name").Nullable {
+					t.Errorf("customers.name = %+v, want a not null varchar", c.Column("name"))
+				}
+				var indexes []Index
+				for _, ix := range c.Indexes {
+					indexes = append(indexes, Index{Name: ix.Name, IsUnique: ix.IsUnique, IsPrimary: ix.IsPrimary, Keys: ix.Keys})
+				}
+				if want := []Index{
+					{Name: "customers_name_key", IsUnique: true, Keys: []string{"name"}},
+					{Name: "customers_pkey", IsUnique: true, IsPrimary: true, Keys: []string{"id"}},
+				}; !reflect.DeepEqual(indexes, want) {
+					t.Errorf("customers indexes = %+v, want %+v", indexes, want)
+				}
+				if len(o.ForeignKeys) != 1 {
+					t.Fatalf("orders has %d foreign keys, want 1", len(o.ForeignKeys))
+				}
+				fk := o.ForeignKeys[0]
+				if fk.RefSchema != "public" || fk.RefTable != "customers" || !reflect.DeepEqual(fk.RefColumns, []string{"id"}) || fk.OnDelete != "CASCADE" || fk.Columns[0] != o.Column("customer_id") {
+					t.Errorf("orders foreign key = %+v", fk)
+				}
+				if len(o.Checks) != 1 || o.Checks[0].Definition != "CHECK ((customer_id > 0)) NOT VALID" {
+					t.Errorf("orders checks = %+v", o.Checks)
+				}
+			},
+		},
+		{
+			name: "owned sequence",
+			src: `
+CREATE TABLE public.customers (id integer NOT NULL);
+CREATE SEQUENCE public.customers_id_seq
+    AS integer
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1;
+ALTER SEQUENCE public.customers_id_seq OWNED BY public.customers.id;
+ALTER TABLE ONLY public.customers ALTER COLUMN id SET DEFAULT nextval('public.customers_id_seq'::regclass);
+CREATE SEQUENCE public.tickets AS bigint;
+`,
+			check: func(t *testing.T, d *PGData) {
+				c := table(t, d, "public.customers").Column("id")
+				if !c.IsSerial() || c.Default != "nextval('public.customers_id_seq'::regclass)" {
+					t.Errorf("customers.id default = %q, want serial", c.Default)
+				}
+				want := map[string]*Sequence{
+					"public.customers_id_seq": {Schema: "public", Name: "customers_id_seq", DataType: "int4", OwnerTable: "public.customers", OwnerColumn: "id"},
+					"public.tickets":          {Schema: "public", Name: "tickets", DataType: "int8"},
+				}
+				if !reflect.DeepEqual(d.Sequences, want) {
+					t.Errorf("sequences = %+v, want %+v", d.Sequences, want)
+				}
+			},
+		},
+		{
+			name: "identity added by pg_dump",
+			src: `
+CREATE TABLE public.events (id bigint NOT NULL, at timestamp with time zone);
+ALTER TABLE public.events ALTER COLUMN id ADD GENERATED ALWAYS AS IDENTITY (
+    SEQUENCE NAME public.events_id_seq
+    START WITH 1
+    INCREMENT BY 1
+    NO MINVALUE
+    NO MAXVALUE
+    CACHE 1
+);
+`,
+			check: func(t *testing.T, d *PGData) {
+				e := table(t, d, "public.events")
+				if id := e.Column("id"); id.Identity != "ALWAYS" || !id.IsReadOnly() {
+					t.Errorf("events.id identity = %q, want ALWAYS", id.Identity)
+				}
+				if at := e.Column("at"); at.DataType != "timestamptz" {
+					t.Errorf("events.at type = %q, want timestamptz", at.DataType)
+				}
+				sq := d.Sequences["public.events_id_seq"]
+				if sq == nil || sq.DataType != "int8" || sq.OwnerTable != "public.events" || sq.OwnerColumn != "id" {
+					t.Errorf("events_id_seq = %+v, want an int8 sequence owned by events.id", sq)
+				}
+			},
+		},
+		{
+			name: "dollar quoting",
+			src: `
+CREATE FUNCTION public.touch() RETURNS trigger LANGUAGE plpgsql AS $$
+BEGIN
+  -- ; isn't the end of the statement
+  NEW.note := 'CREATE TABLE public.nope (id int);';
+  RETURN NEW;
+END;
+$$;
+CREATE FUNCTION public.add(a integer, b integer) RETURNS integer LANGUAGE sql AS $body$ SELECT $1 + $2; $x$ $body$;
+CREATE TABLE public.notes (note text DEFAULT $q$it's  "quoted"$q$);
+`,
+			check: func(t *testing.T, d *PGData) {
+				if _, ok := d.Tables["public.nope"]; ok {
+					t.Error("table created inside a function body was parsed")
+				}
+				if c := table(t, d, "public.notes").Column("note"); c.Default != `$q$it's  "quoted"$q$` {
+					t.Errorf("notes.note default = %q", c.Default)
+				}
+				if f := d.Functions["public.add(int4,int4)"]; f == nil || len(f.Results) != 1 || f.Results[0].DataType != "int4" {
+					t.Errorf("add = %+v, want a function of two integers returning one", f)
+				}
+			},
+		},
+		{
+			name: "nested comments",
+			src: `
+/* outer /* inner */ still a comment;
+CREATE TABLE public.nope (id int); */
+-- CREATE TABLE public.nope2 (id int);
+CREATE TABLE public.kept (
+    id int, -- trailing comment
+    /* before */ name text
+);
+`,
+			check: func(t *testing.T, d *PGData) {
+				if len(d.Tables) != 1 {
+					t.Errorf("tables = %v, want only public.kept", keys(d.Tables))
+				}
+				k := table(t, d, "public.kept")
+				if len(k.Columns) != 2 || k.Columns[1].Name != "name" {
+					t.Errorf("kept columns = %+v, want id and name", k.Columns)
+				}
+			},
+		},
+		{
+			name: "whitespace in expressions",
+			src: `
+CREATE TABLE public.t (
+    s text DEFAULT 'line1
+line2'::text,
+    e text DEFAULT E'a\tb',
+    n integer DEFAULT (1 +
+        2),
+    m integer DEFAULT 1 /* one */   + 2,
+    CONSTRAINT s_in CHECK ((s = ANY (ARRAY['a  b'::text, 'c'::text])))
+);
+`,
+			check: func(t *testing.T, d *PGData) {
+				tt := table(t, d, "public.t")
+				for name, want := range map[string]string{
+					"s": "'line1\nline2'::text",
+					"e": `E'a\tb'`,
+					"n": "(1 + 2)",
+					"m": "1 + 2",
+				} {
+					if got := tt.Column(name).Default; got != want {
+						t.Errorf("default of %s = %q, want %q", name, got, want)
+					}
+				}
+				if len(tt.Checks) != 1 || tt.Checks[0].Definition != "CHECK ((s = ANY (ARRAY['a  b'::text, 'c'::text])))" {
+					t.Errorf("checks = %+v", tt.Checks)
+				}
+			},
+		},
+		{
+			name: "search path and quoted names",
+			src: `
+SELECT pg_catalog.set_config('search_path', '', false);
+CREATE SCHEMA auth;
+set search_path to auth, public;
+CREATE TABLE "Odd" ("Key" text PRIMARY KEY, "Other" int);
+CREATE TABLE public.plain (id int);
+`,
+			check: func(t *testing.T, d *PGData) {
+				if len(d.Tables) != 1 {
+					t.Errorf("tables = %v, want only public.plain", keys(d.Tables))
+				}
+				table(t, d, "public.plain")
+			},
+		},
+		{
+			name: "enum values",
+			src: `
+CREATE TYPE public.mood AS ENUM ('happy', 'sad');
+ALTER TYPE public.mood ADD VALUE 'meh' BEFORE 'sad';
+ALTER TYPE public.mood ADD VALUE IF NOT EXISTS 'glad' AFTER 'happy';
+ALTER TYPE public.mood ADD VALUE 'angry';
+`,
+			check: func(t *testing.T, d *PGData) {
+				en := d.Enums["public.mood"]
+				if en == nil {
+					t.Fatal("no enum public.mood")
+				}
+				var got []string
+				for _, v := range en.Values {
+					got = append(got, v.Value)
+				}
+				if want := []string{"happy", "glad", "meh", "sad", "angry"}; !reflect.DeepEqual(got, want) {
+					t.Errorf("mood values = %v, want %v", got, want)
+				}
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d, err := ParseDDL(tt.src, "public")
+			if err != nil {
+				t.Fatalf("ParseDDL: %v", err)
+			}
+			tt.check(t, d)
+		})
+	}
+}
+
+func TestParseDDLErrors(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{"CREATE TABLE t (\n  s text DEFAULT 'open\n);", "line 2: unterminated string"},
+		{"CREATE FUNCTION f() RETURNS int AS $$ SELECT 1;", "line 1: unterminated dollar quoted string"},
+		{"CREATE TABLE \"t (id int);", "line 1: unterminated quoted identifier"},
+	}
+	for _, tt := range tests {
+		_, err := ParseDDL(tt.src)
+		if err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("ParseDDL(%q) = %v, want an error containing %q", tt.src, err, tt.want)
+		}
+	}
+}
+
+func table(t *testing.T, d *PGData, name string) *Table {
+	tb, ok := d.Tables[name]
+	if !ok {
+		t.Fatalf("no table %s, have %v", name, keys(d.Tables))
+	}
+	return tb
+}
+
+func keys(m map[string]*Table) []string {
+	var kk []string
+	for k := range m {
+		kk = append(kk, k)
+	}
+	return kk
+}
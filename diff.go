@@ -0,0 +1,895 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Change is a difference between two states of the inspected schemas.
+type Change struct {
+	// Op is '+' for added, '-' for removed and '~' for changed objects.
+	Op string
+	// Kind is the kind of object changed, e.g. 'table' or 'enum value', and
+	// Name its schema qualified name.
+	Kind string
+	Name string
+	// Detail describes what changed, e.g. 'type text -> int4'.
+	Detail string
+	// SQL is the DDL taking the old state to the new one, or an SQL comment
+	// where there is none, e.g. for removed enum values.
+	SQL string
+
+	phase int
+}
+
+func (c *Change) String() string {
+	s := c.Op + " " + c.Kind + " " + c.Name
+	if c.Detail != "" {
+		s += ": " + c.Detail
+	}
+	return s
+}
+
+// The phases changes are ordered in, so the DDL of each applies after
+// what it depends on: foreign keys are dropped before the tables they
+// reference, types are created before the columns using them, sequences
+// are dropped after the defaults using them, and so on.
+const (
+	phaseDropForeignKeys = iota
+	phaseDropConstraints
+	phaseDropTables
+	phaseDropTypes
+	phaseTypes
+	phaseTables
+	phaseDropSequences
+	phaseConstraints
+	phaseForeignKeys
+)
+
+type differ struct {
+	changes []*Change
+	// from and to are the states compared, holding the parents of the
+	// tables created and the columns owning sequences.
+	from, to *PGData
+}
+
+func (d *differ) add(phase int, op, kind, name, detail string, sql ...string) {
+	d.changes = append(d.changes, &Change{Op: op, Kind: kind, Name: name, Detail: detail, SQL: strings.Join(sql, "\n"), phase: phase})
+}
+
+// Diff returns the changes from the schemas in from to those in to, in the
+// order their DDL applies.
+//
+// Type modifiers other than the precision and scale of numeric columns,
+// such as varchar lengths, aren't inspected, so they don't show up as
+// changes or in the DDL. Views are reported, but their DDL is
+// left as a comment since their definitions aren't inspected. Functions
+// aren't compared, and the sequences of serial and identity columns are
+// left to the DDL of their columns.
+func Diff(from, to *PGData) []*Change {
+	d := &differ{from: from, to: to}
+	for _, k := range unionKeys(from.Enums, to.Enums) {
+		d.enum(from.Enums[k], to.Enums[k])
+	}
+	for _, k := range unionKeys(from.Domains, to.Domains) {
+		d.domain(from.Domains[k], to.Domains[k])
+	}
+	for _, k := range unionKeys(from.Composites, to.Composites) {
+		d.composite(from.Composites[k], to.Composites[k])
+	}
+	for _, k := range unionKeys(from.Sequences, to.Sequences) {
+		d.sequence(from.Sequences[k], to.Sequences[k])
+	}
+	for _, k := range unionKeys(from.Tables, to.Tables) {
+		d.table(from.Tables[k], to.Tables[k])
+	}
+	sort.SliceStable(d.changes, func(i, j int) bool { return d.changes[i].phase < d.changes[j].phase })
+	return d.changes
+}
+
+// unionKeys returns the sorted keys of two maps of the same type.
+func unionKeys(a, b interface{}) []string {
+	seen := map[string]bool{}
+	for _, m := range []interface{}{a, b} {
+		switch m := m.(type) {
+		case map[string]*Enum:
+			for k := range m {
+				seen[k] = true
+			}
+		case map[string]*Domain:
+			for k := range m {
+				seen[k] = true
+			}
+		case map[string]*Composite:
+			for k := range m {
+				seen[k] = true
+			}
+		case map[string]*Table:
+			for k := range m {
+				seen[k] = true
+			}
+		case map[string]*Sequence:
+			for k := range m {
+				seen[k] = true
+			}
+		}
+	}
+	var kk []string
+	for k := range seen {
+		kk = append(kk, k)
+	}
+	sort.Strings(kk)
+	return kk
+}
+
+func (d *differ) enum(a, b *Enum) {
+	switch {
+	case a == nil:
+		var vv []string
+		for _, v := range b.Values {
+			vv = append(vv, quoteLiteral(v.Value))
+		}
+		d.add(phaseTypes, "+", "enum", b.QualifiedName(), "",
+			fmt.Sprintf("CREATE TYPE %s AS ENUM (%s);", quoteName(b.Schema, b.Name), strings.Join(vv, ", ")))
+		return
+	case b == nil:
+		d.add(phaseDropTypes, "-", "enum", a.QualifiedName(), "",
+			fmt.Sprintf("DROP TYPE %s;", quoteName(a.Schema, a.Name)))
+		return
+	}
+
+	has := func(en *Enum, v string) bool {
+		for _, ev := range en.Values {
+			if ev.Value == v {
+				return true
+			}
+		}
+		return false
+	}
+	name := quoteName(b.Schema, b.Name)
+	for k, v := range b.Values {
+		if has(a, v.Value) {
+			continue
+		}
+		sql := fmt.Sprintf("ALTER TYPE %s ADD VALUE %s", name, quoteLiteral(v.Value))
+		switch {
+		case k > 0:
+			sql += " AFTER " + quoteLiteral(b.Values[k-1].Value)
+		case len(b.Values) > 1:
+			sql += " BEFORE " + quoteLiteral(b.Values[1].Value)
+		}
+		d.add(phaseTypes, "+", "enum value", b.QualifiedName()+"."+v.Value, "", sql+";")
+	}
+	for _, v := range a.Values {
+		if !has(b, v.Value) {
+			d.add(phaseDropTypes, "-", "enum value", a.QualifiedName()+"."+v.Value, "",
+				fmt.Sprintf("-- enum values can't be removed; recreate %s without %s", name, quoteLiteral(v.Value)))
+		}
+	}
+}
+
+func (d *differ) domain(a, b *Domain) {
+	switch {
+	case a == nil:
+		sql := fmt.Sprintf("CREATE DOMAIN %s AS %s", quoteName(b.Schema, b.Name), typeDDL(b.Base()))
+		if b.Default != "" {
+			sql += " DEFAULT " + b.Default
+		}
+		if b.NotNull {
+			sql += " NOT NULL"
+		}
+		for _, ck := range b.Checks {
+			sql += " " + ck
+		}
+		d.add(phaseTypes, "+", "domain", b.QualifiedName(), "", sql+";")
+		return
+	case b == nil:
+		d.add(phaseDropTypes, "-", "domain", a.QualifiedName(), "",
+			fmt.Sprintf("DROP DOMAIN %s;", quoteName(a.Schema, a.Name)))
+		return
+	}
+
+	name := quoteName(b.Schema, b.Name)
+	var details, sql []string
+	if at, bt := typeDDL(a.Base()), typeDDL(b.Base()); at != bt {
+		details = append(details, "type "+at+" -> "+bt)
+		sql = append(sql, fmt.Sprintf("-- the base type of a domain can't be changed; recreate %s as %s", name, bt))
+	}
+	if a.NotNull != b.NotNull {
+		details = append(details, nullDetail(!a.NotNull, !b.NotNull))
+		if b.NotNull {
+			sql = append(sql, fmt.Sprintf("ALTER DOMAIN %s SET NOT NULL;", name))
+		} else {
+			sql = append(sql, fmt.Sprintf("ALTER DOMAIN %s DROP NOT NULL;", name))
+		}
+	}
+	if a.Default != b.Default {
+		details = append(details, defaultDetail(a.Default, b.Default))
+		if b.Default != "" {
+			sql = append(sql, fmt.Sprintf("ALTER DOMAIN %s SET DEFAULT %s;", name, b.Default))
+		} else {
+			sql = append(sql, fmt.Sprintf("ALTER DOMAIN %s DROP DEFAULT;", name))
+		}
+	}
+	for _, ck := range stringsNotIn(b.Checks, a.Checks) {
+		details = append(details, "added "+ck)
+		sql = append(sql, fmt.Sprintf("ALTER DOMAIN %s ADD %s;", name, ck))
+	}
+	for _, ck := range stringsNotIn(a.Checks, b.Checks) {
+		details = append(details, "removed "+ck)
+		sql = append(sql, fmt.Sprintf("-- drop the constraint of %s that is %s", name, ck))
+	}
+	if len(details) > 0 {
+		d.add(phaseTypes, "~", "domain", b.QualifiedName(), strings.Join(details, ", "), sql...)
+	}
+}
+
+func (d *differ) composite(a, b *Composite) {
+	switch {
+	case a == nil:
+		var attrs []string
+		for _, c := range b.Attributes {
+			attrs = append(attrs, quoteIdent(c.Name)+" "+typeDDL(c))
+		}
+		d.add(phaseTypes, "+", "composite type", b.QualifiedName(), "",
+			fmt.Sprintf("CREATE TYPE %s AS (%s);", quoteName(b.Schema, b.Name), strings.Join(attrs, ", ")))
+		return
+	case b == nil:
+		d.add(phaseDropTypes, "-", "composite type", a.QualifiedName(), "",
+			fmt.Sprintf("DROP TYPE %s;", quoteName(a.Schema, a.Name)))
+		return
+	}
+
+	name := quoteName(b.Schema, b.Name)
+	for _, n := range unionColumns(a.Attributes, b.Attributes) {
+		ac, bc := columnNamed(a.Attributes, n), columnNamed(b.Attributes, n)
+		qn := b.QualifiedName() + "." + n
+		switch {
+		case ac == nil:
+			d.add(phaseTypes, "+", "attribute", qn, "",
+				fmt.Sprintf("ALTER TYPE %s ADD ATTRIBUTE %s %s;", name, quoteIdent(n), typeDDL(bc)))
+		case bc == nil:
+			d.add(phaseDropTypes, "-", "attribute", qn, "",
+				fmt.Sprintf("ALTER TYPE %s DROP ATTRIBUTE %s;", name, quoteIdent(n)))
+		case typeDDL(ac) != typeDDL(bc):
+			d.add(phaseTypes, "~", "attribute", qn, "type "+typeDDL(ac)+" -> "+typeDDL(bc),
+				fmt.Sprintf("ALTER TYPE %s ALTER ATTRIBUTE %s TYPE %s;", name, quoteIdent(n), typeDDL(bc)))
+		}
+	}
+}
+
+func (d *differ) sequence(a, b *Sequence) {
+	switch {
+	case a == nil:
+		if c := b.owner(d.to); c != nil && (c.Identity != "" || c.IsSerial()) {
+			if old := b.owner(d.from); old == nil || c.Identity != "" && old.Identity == "" {
+				// Created by the DDL adding the column or its identity.
+				return
+			}
+		}
+		sql := []string{fmt.Sprintf("CREATE SEQUENCE %s AS %s;", quoteName(b.Schema, b.Name), b.DataType)}
+		if b.Comment != "" {
+			sql = append(sql, fmt.Sprintf("COMMENT ON SEQUENCE %s IS %s;", quoteName(b.Schema, b.Name), commentLiteral(b.Comment)))
+		}
+		d.add(phaseTypes, "+", "sequence", b.QualifiedName(), "", sql...)
+		if b.IsOwned() {
+			// The table may only be created later.
+			d.add(phaseConstraints, "~", "sequence", b.QualifiedName(), "owned by "+b.ownerName(),
+				fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s;", quoteName(b.Schema, b.Name), quoteQualified(b.OwnerTable), quoteIdent(b.OwnerColumn)))
+		}
+		return
+	case b == nil:
+		if old := a.owner(d.from); old != nil {
+			if c := a.owner(d.to); c == nil || old.Identity != "" && c.Identity == "" {
+				// Dropped along with the column or its identity.
+				return
+			}
+		}
+		d.add(phaseDropSequences, "-", "sequence", a.QualifiedName(), "",
+			fmt.Sprintf("DROP SEQUENCE %s;", quoteName(a.Schema, a.Name)))
+		return
+	}
+
+	name := quoteName(b.Schema, b.Name)
+	var details, sql []string
+	if a.DataType != b.DataType {
+		details = append(details, "type "+a.DataType+" -> "+b.DataType)
+		sql = append(sql, fmt.Sprintf("ALTER SEQUENCE %s AS %s;", name, b.DataType))
+	}
+	if ao, bo := a.ownerName(), b.ownerName(); ao != bo {
+		details = append(details, "owned by "+orNone(ao)+" -> "+orNone(bo))
+		if !b.IsOwned() {
+			sql = append(sql, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY NONE;", name))
+		} else {
+			sql = append(sql, fmt.Sprintf("ALTER SEQUENCE %s OWNED BY %s.%s;", name, quoteQualified(b.OwnerTable), quoteIdent(b.OwnerColumn)))
+		}
+	}
+	if a.Comment != b.Comment {
+		details = append(details, "comment")
+		sql = append(sql, fmt.Sprintf("COMMENT ON SEQUENCE %s IS %s;", name, commentLiteral(b.Comment)))
+	}
+	if len(details) > 0 {
+		d.add(phaseConstraints, "~", "sequence", b.QualifiedName(), strings.Join(details, ", "), sql...)
+	}
+}
+
+func (d *differ) table(a, b *Table) {
+	switch {
+	case a == nil:
+		d.createTable(b)
+		return
+	case b == nil:
+		d.add(phaseDropTables, "-", a.Kind, a.QualifiedName(), "",
+			fmt.Sprintf("DROP %s %s;", strings.ToUpper(a.Kind), quoteName(a.Schema, a.Name)))
+		return
+	case a.Kind != b.Kind:
+		d.add(phaseDropTables, "-", a.Kind, a.QualifiedName(), "",
+			fmt.Sprintf("DROP %s %s;", strings.ToUpper(a.Kind), quoteName(a.Schema, a.Name)))
+		d.createTable(b)
+		return
+	}
+
+	name := quoteName(b.Schema, b.Name)
+	if a.PartitionKey != b.PartitionKey {
+		d.add(phaseTables, "~", b.Kind, b.QualifiedName(), "partition key "+orNone(a.PartitionKey)+" -> "+orNone(b.PartitionKey),
+			fmt.Sprintf("-- the partition key of %s can't be changed; recreate it", name))
+	}
+	d.inheritance(a, b)
+	if a.Comment != b.Comment {
+		d.add(phaseTables, "~", b.Kind, b.QualifiedName(), "comment",
+			fmt.Sprintf("COMMENT ON %s %s IS %s;", strings.ToUpper(b.Kind), name, commentLiteral(b.Comment)))
+	}
+	// What the table gets from its parents changes along with them, and
+	// can't be altered on the table itself.
+	from, to := parentsOf(d.from, a), parentsOf(d.to, b)
+	for _, n := range unionColumns(a.Columns, b.Columns) {
+		ac, bc := columnNamed(a.Columns, n), columnNamed(b.Columns, n)
+		if bc != nil && inheritedColumn(to, bc) || bc == nil && inheritedColumn(from, ac) {
+			continue
+		}
+		d.column(b, ac, bc)
+	}
+	d.indexes(b, ownIndexes(from, a.Indexes), ownIndexes(to, b.Indexes))
+	d.foreignKeys(b, ownForeignKeys(from, a.ForeignKeys), ownForeignKeys(to, b.ForeignKeys))
+	d.checks(b, ownChecks(from, a.Checks), ownChecks(to, b.Checks))
+}
+
+func (d *differ) createTable(t *Table) {
+	name := quoteName(t.Schema, t.Name)
+	if t.Kind != KindTable {
+		d.add(phaseTables, "+", t.Kind, t.QualifiedName(), "",
+			fmt.Sprintf("-- the definition of %s %s isn't inspected; create it by hand", t.Kind, name))
+		return
+	}
+	// Columns, checks, keys and indexes the table gets from its parents
+	// aren't repeated.
+	parents := parentsOf(d.to, t)
+
+	var defs []string
+	for _, c := range t.Columns {
+		if !inheritedColumn(parents, c) {
+			defs = append(defs, "    "+columnDDL(c))
+		}
+	}
+	var indexes []*Index
+	for _, ix := range ownIndexes(parents, t.Indexes) {
+		if ix.IsPrimary {
+			defs = append(defs, fmt.Sprintf("    CONSTRAINT %s PRIMARY KEY (%s)", quoteIdent(ix.Name), strings.Join(ix.Keys, ", ")))
+			continue
+		}
+		indexes = append(indexes, ix)
+	}
+	var create string
+	switch {
+	case t.PartitionBound != "" && len(t.Inherits) == 1:
+		create = fmt.Sprintf("CREATE TABLE %s PARTITION OF %s", name, quoteQualified(t.Inherits[0]))
+		if len(defs) > 0 {
+			create += fmt.Sprintf(" (\n%s\n)", strings.Join(defs, ",\n"))
+		}
+		create += " " + t.PartitionBound
+	default:
+		create = fmt.Sprintf("CREATE TABLE %s (\n%s\n)", name, strings.Join(defs, ",\n"))
+		if len(t.Inherits) > 0 {
+			var pp []string
+			for _, n := range t.Inherits {
+				pp = append(pp, quoteQualified(n))
+			}
+			create += " INHERITS (" + strings.Join(pp, ", ") + ")"
+		}
+	}
+	if t.PartitionKey != "" {
+		create += " PARTITION BY " + t.PartitionKey
+	}
+	sql := []string{create + ";"}
+	if t.Comment != "" {
+		sql = append(sql, fmt.Sprintf("COMMENT ON TABLE %s IS %s;", name, commentLiteral(t.Comment)))
+	}
+	for _, c := range t.Columns {
+		if c.Comment != "" {
+			sql = append(sql, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", name, quoteIdent(c.Name), commentLiteral(c.Comment)))
+		}
+	}
+	d.add(phaseTables, "+", "table", t.QualifiedName(), "", sql...)
+	d.indexes(t, nil, indexes)
+	d.foreignKeys(t, nil, ownForeignKeys(parents, t.ForeignKeys))
+	d.checks(t, nil, ownChecks(parents, t.Checks))
+}
+
+// parentsOf returns the tables of data t inherits from or is a partition of.
+func parentsOf(data *PGData, t *Table) []*Table {
+	var parents []*Table
+	for _, n := range t.Inherits {
+		if p := data.Tables[n]; p != nil {
+			parents = append(parents, p)
+		}
+	}
+	return parents
+}
+
+// ownIndexes returns the indexes of ixs not inherited from parents.
+func ownIndexes(parents []*Table, ixs []*Index) []*Index {
+	var own []*Index
+	for _, ix := range ixs {
+		if !inheritedIndex(parents, ix) {
+			own = append(own, ix)
+		}
+	}
+	return own
+}
+
+// ownForeignKeys returns the foreign keys of fks not inherited from parents.
+func ownForeignKeys(parents []*Table, fks []*ForeignKey) []*ForeignKey {
+	var own []*ForeignKey
+	for _, fk := range fks {
+		if !inheritedForeignKey(parents, fk) {
+			own = append(own, fk)
+		}
+	}
+	return own
+}
+
+// ownChecks returns the checks of cks not inherited from parents.
+func ownChecks(parents []*Table, cks []*Check) []*Check {
+	var own []*Check
+	for _, ck := range cks {
+		if !inheritedCheck(parents, ck) {
+			own = append(own, ck)
+		}
+	}
+	return own
+}
+
+// inheritance reports the table becoming or ceasing to be a partition or
+// child of another.
+func (d *differ) inheritance(a, b *Table) {
+	name := quoteName(b.Schema, b.Name)
+	if a.PartitionBound != "" || b.PartitionBound != "" {
+		if a.PartitionBound == b.PartitionBound && strings.Join(a.Inherits, ",") == strings.Join(b.Inherits, ",") {
+			return
+		}
+		var sql []string
+		if a.PartitionBound != "" && len(a.Inherits) == 1 {
+			sql = append(sql, fmt.Sprintf("ALTER TABLE %s DETACH PARTITION %s;", quoteQualified(a.Inherits[0]), name))
+		}
+		if b.PartitionBound != "" && len(b.Inherits) == 1 {
+			sql = append(sql, fmt.Sprintf("ALTER TABLE %s ATTACH PARTITION %s %s;", quoteQualified(b.Inherits[0]), name, b.PartitionBound))
+		}
+		d.add(phaseTables, "~", b.Kind, b.QualifiedName(), "partition "+orNone(partitionOf(a))+" -> "+orNone(partitionOf(b)), sql...)
+		return
+	}
+	var sql []string
+	for _, n := range stringsNotIn(a.Inherits, b.Inherits) {
+		sql = append(sql, fmt.Sprintf("ALTER TABLE %s NO INHERIT %s;", name, quoteQualified(n)))
+	}
+	for _, n := range stringsNotIn(b.Inherits, a.Inherits) {
+		sql = append(sql, fmt.Sprintf("ALTER TABLE %s INHERIT %s;", name, quoteQualified(n)))
+	}
+	if len(sql) > 0 {
+		d.add(phaseTables, "~", b.Kind, b.QualifiedName(), "inherits "+orNone(strings.Join(a.Inherits, ", "))+" -> "+orNone(strings.Join(b.Inherits, ", ")), sql...)
+	}
+}
+
+func partitionOf(t *Table) string {
+	if t.PartitionBound == "" {
+		return ""
+	}
+	return strings.Join(t.Inherits, ", ") + " " + t.PartitionBound
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
+}
+
+func inheritedColumn(parents []*Table, c *Column) bool {
+	for _, p := range parents {
+		if p.Column(c.Name) != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// inheritedIndex reports whether ix is the partition's copy of an index of
+// its parent, which the server creates along with the partition.
+func inheritedIndex(parents []*Table, ix *Index) bool {
+	for _, p := range parents {
+		for _, pix := range p.Indexes {
+			if pix.IsUnique == ix.IsUnique && pix.IsPrimary == ix.IsPrimary && pix.Predicate == ix.Predicate &&
+				strings.Join(pix.Keys, ",") == strings.Join(ix.Keys, ",") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inheritedForeignKey(parents []*Table, fk *ForeignKey) bool {
+	for _, p := range parents {
+		for _, pfk := range p.ForeignKeys {
+			if pfk.Name == fk.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func inheritedCheck(parents []*Table, ck *Check) bool {
+	for _, p := range parents {
+		for _, pck := range p.Checks {
+			if pck.Name == ck.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (d *differ) column(t *Table, a, b *Column) {
+	name := quoteName(t.Schema, t.Name)
+	readOnly := t.Kind != KindTable
+	switch {
+	case a == nil:
+		sql := fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s;", name, columnDDL(b))
+		if readOnly {
+			sql = "-- " + sql
+		}
+		d.add(phaseTables, "+", "column", t.QualifiedName()+"."+b.Name, "", sql)
+		return
+	case b == nil:
+		sql := fmt.Sprintf("ALTER TABLE %s DROP COLUMN %s;", name, quoteIdent(a.Name))
+		if readOnly {
+			sql = "-- " + sql
+		}
+		d.add(phaseDropTables, "-", "column", t.QualifiedName()+"."+a.Name, "", sql)
+		return
+	}
+
+	col := quoteIdent(b.Name)
+	var details, actions, notes []string
+	if at, bt := typeDDL(a), typeDDL(b); at != bt {
+		details = append(details, "type "+at+" -> "+bt)
+		actions = append(actions, fmt.Sprintf("ALTER COLUMN %s TYPE %s", col, bt))
+	}
+	if a.Nullable != b.Nullable {
+		details = append(details, nullDetail(a.Nullable, b.Nullable))
+		if b.Nullable {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP NOT NULL", col))
+		} else {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET NOT NULL", col))
+		}
+	}
+	if a.Default != b.Default {
+		details = append(details, defaultDetail(a.Default, b.Default))
+		if b.Default != "" {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET DEFAULT %s", col, b.Default))
+		} else {
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP DEFAULT", col))
+		}
+	}
+	if a.Identity != b.Identity {
+		details = append(details, fmt.Sprintf("identity %q -> %q", a.Identity, b.Identity))
+		switch {
+		case b.Identity == "":
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s DROP IDENTITY", col))
+		case a.Identity == "":
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s ADD GENERATED %s AS IDENTITY", col, b.Identity))
+		default:
+			actions = append(actions, fmt.Sprintf("ALTER COLUMN %s SET GENERATED %s", col, b.Identity))
+		}
+	}
+	if a.Generated != b.Generated {
+		details = append(details, fmt.Sprintf("generated %q -> %q", a.Generated, b.Generated))
+		notes = append(notes, fmt.Sprintf("-- generated columns can't be altered; drop %s and add it as %s", col, columnDDL(b)))
+	}
+	if a.Comment != b.Comment {
+		details = append(details, "comment")
+		notes = append(notes, fmt.Sprintf("COMMENT ON COLUMN %s.%s IS %s;", name, col, commentLiteral(b.Comment)))
+	}
+	if len(details) == 0 {
+		return
+	}
+	var sql []string
+	if len(actions) > 0 {
+		s := fmt.Sprintf("ALTER TABLE %s\n    %s;", name, strings.Join(actions, ",\n    "))
+		if readOnly {
+			s = "-- " + strings.Replace(s, "\n", "\n-- ", -1)
+		}
+		sql = append(sql, s)
+	}
+	d.add(phaseTables, "~", "column", t.QualifiedName()+"."+b.Name, strings.Join(details, ", "), append(sql, notes...)...)
+}
+
+func (d *differ) indexes(t *Table, from, to []*Index) {
+	name := quoteName(t.Schema, t.Name)
+	create := func(ix *Index) string {
+		if ix.IsPrimary {
+			return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s PRIMARY KEY (%s);", name, quoteIdent(ix.Name), strings.Join(ix.Keys, ", "))
+		}
+		s := "CREATE INDEX"
+		if ix.IsUnique {
+			s = "CREATE UNIQUE INDEX"
+		}
+		s += fmt.Sprintf(" %s ON %s", quoteIdent(ix.Name), name)
+		if ix.Method != "" {
+			s += " USING " + ix.Method
+		}
+		s += " (" + strings.Join(ix.Keys, ", ") + ")"
+		if ix.Predicate != "" {
+			s += " WHERE " + ix.Predicate
+		}
+		return s + ";"
+	}
+	drop := func(ix *Index) string {
+		if ix.IsPrimary {
+			return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", name, quoteIdent(ix.Name))
+		}
+		return fmt.Sprintf("DROP INDEX %s;", quoteName(t.Schema, ix.Name))
+	}
+	same := func(a, b *Index) bool {
+		return a.Method == b.Method && a.IsUnique == b.IsUnique && a.IsPrimary == b.IsPrimary &&
+			a.Predicate == b.Predicate && strings.Join(a.Keys, ",") == strings.Join(b.Keys, ",")
+	}
+
+	byName := map[string]*Index{}
+	for _, ix := range to {
+		byName[ix.Name] = ix
+	}
+	for _, a := range from {
+		b, ok := byName[a.Name]
+		qn := t.QualifiedName() + "." + a.Name
+		switch {
+		case !ok:
+			d.add(phaseDropConstraints, "-", "index", qn, "", drop(a))
+		case !same(a, b):
+			d.add(phaseConstraints, "~", "index", qn, create(a)+" -> "+create(b), drop(a), create(b))
+		}
+		delete(byName, a.Name)
+	}
+	for _, b := range to {
+		if _, ok := byName[b.Name]; ok {
+			d.add(phaseConstraints, "+", "index", t.QualifiedName()+"."+b.Name, "", create(b))
+		}
+	}
+}
+
+func (d *differ) foreignKeys(t *Table, from, to []*ForeignKey) {
+	name := quoteName(t.Schema, t.Name)
+	def := func(fk *ForeignKey) string {
+		var cols, refs []string
+		for _, c := range fk.Columns {
+			cols = append(cols, quoteIdent(c.Name))
+		}
+		for _, c := range fk.RefColumns {
+			refs = append(refs, quoteIdent(c))
+		}
+		return fmt.Sprintf("FOREIGN KEY (%s) REFERENCES %s (%s) ON DELETE %s ON UPDATE %s",
+			strings.Join(cols, ", "), quoteName(fk.RefSchema, fk.RefTable), strings.Join(refs, ", "), fk.OnDelete, fk.OnUpdate)
+	}
+	create := func(fk *ForeignKey) string {
+		return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", name, quoteIdent(fk.Name), def(fk))
+	}
+	drop := func(fk *ForeignKey) string {
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", name, quoteIdent(fk.Name))
+	}
+
+	byName := map[string]*ForeignKey{}
+	for _, fk := range to {
+		byName[fk.Name] = fk
+	}
+	for _, a := range from {
+		b, ok := byName[a.Name]
+		qn := t.QualifiedName() + "." + a.Name
+		switch {
+		case !ok:
+			d.add(phaseDropForeignKeys, "-", "foreign key", qn, "", drop(a))
+		case def(a) != def(b):
+			d.add(phaseForeignKeys, "~", "foreign key", qn, def(a)+" -> "+def(b), drop(a), create(b))
+		}
+		delete(byName, a.Name)
+	}
+	for _, b := range to {
+		if _, ok := byName[b.Name]; ok {
+			d.add(phaseForeignKeys, "+", "foreign key", t.QualifiedName()+"."+b.Name, "", create(b))
+		}
+	}
+}
+
+func (d *differ) checks(t *Table, from, to []*Check) {
+	name := quoteName(t.Schema, t.Name)
+	create := func(ck *Check) string {
+		return fmt.Sprintf("ALTER TABLE %s ADD CONSTRAINT %s %s;", name, quoteIdent(ck.Name), ck.Definition)
+	}
+	drop := func(ck *Check) string {
+		return fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT %s;", name, quoteIdent(ck.Name))
+	}
+
+	byName := map[string]*Check{}
+	for _, ck := range to {
+		byName[ck.Name] = ck
+	}
+	for _, a := range from {
+		b, ok := byName[a.Name]
+		qn := t.QualifiedName() + "." + a.Name
+		switch {
+		case !ok:
+			d.add(phaseDropConstraints, "-", "check", qn, "", drop(a))
+		case a.Definition != b.Definition:
+			d.add(phaseConstraints, "~", "check", qn, a.Definition+" -> "+b.Definition, drop(a), create(b))
+		}
+		delete(byName, a.Name)
+	}
+	for _, b := range to {
+		if _, ok := byName[b.Name]; ok {
+			d.add(phaseConstraints, "+", "check", t.QualifiedName()+"."+b.Name, "", create(b))
+		}
+	}
+}
+
+// unionColumns returns the names of the columns of a, followed by those
+// only b has, each in column order.
+func unionColumns(a, b []*Column) []string {
+	var nn []string
+	for _, c := range a {
+		nn = append(nn, c.Name)
+	}
+	for _, c := range b {
+		if columnNamed(a, c.Name) == nil {
+			nn = append(nn, c.Name)
+		}
+	}
+	return nn
+}
+
+func columnNamed(cc []*Column, name string) *Column {
+	for _, c := range cc {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// stringsNotIn returns the strings of a which aren't in b.
+func stringsNotIn(a, b []string) []string {
+	var r []string
+	for _, s := range a {
+		found := false
+		for _, t := range b {
+			found = found || s == t
+		}
+		if !found {
+			r = append(r, s)
+		}
+	}
+	return r
+}
+
+func nullDetail(a, b bool) string {
+	n := map[bool]string{true: "null", false: "not null"}
+	return n[a] + " -> " + n[b]
+}
+
+func defaultDetail(a, b string) string {
+	return fmt.Sprintf("default %q -> %q", a, b)
+}
+
+// columnDDL returns the definition of c in CREATE TABLE. Columns defaulting
+// to their own sequence are declared serial, which creates the sequence.
+func columnDDL(c *Column) string {
+	s := quoteIdent(c.Name) + " " + typeDDL(c)
+	serial := map[string]string{"int2": "smallserial", "int4": "serial", "int8": "bigserial"}
+	switch {
+	case c.Generated != "":
+		s += " GENERATED ALWAYS AS (" + c.Generated + ") STORED"
+	case c.Identity != "":
+		s += " GENERATED " + c.Identity + " AS IDENTITY"
+	case c.IsSerial() && serial[c.DataType] != "" && c.Domain == "":
+		s = quoteIdent(c.Name) + " " + serial[c.DataType]
+	case c.Default != "":
+		s += " DEFAULT " + c.Default
+	}
+	if !c.Nullable {
+		s += " NOT NULL"
+	}
+	return s
+}
+
+// typeDDL returns the SQL name of the type of c, e.g. 'int4[]',
+// 'numeric(10,2)' or 'public.mood'.
+func typeDDL(c *Column) string {
+	if c.Domain != "" {
+		parts := strings.SplitN(c.Domain, ".", 2)
+		return quoteName(parts[0], parts[1])
+	}
+	name, array := c.DataType, ""
+	if strings.HasPrefix(name, "_") {
+		name, array = name[1:], "[]"
+	}
+	if c.TypeSchema == "" || c.TypeSchema == "pg_catalog" {
+		return name + c.Modifier() + array
+	}
+	return quoteName(c.TypeSchema, name) + array
+}
+
+var plainIdent = regexp.MustCompile(`^[a-z_][a-z0-9_$]*$`)
+
+// reservedWords are the reserved key words likely to be used as names,
+// which have to be quoted.
+var reservedWords = map[string]bool{
+	"all": true, "analyse": true, "analyze": true, "and": true, "any": true, "array": true, "as": true,
+	"asc": true, "check": true, "collate": true, "column": true, "constraint": true, "create": true,
+	"default": true, "desc": true, "distinct": true, "do": true, "else": true, "end": true, "false": true,
+	"for": true, "foreign": true, "from": true, "grant": true, "group": true, "having": true, "in": true,
+	"limit": true, "not": true, "null": true, "offset": true, "on": true, "only": true, "or": true,
+	"order": true, "primary": true, "references": true, "select": true, "table": true, "then": true,
+	"to": true, "true": true, "union": true, "unique": true, "user": true, "using": true, "when": true,
+	"where": true, "with": true,
+}
+
+func quoteIdent(s string) string {
+	if plainIdent.MatchString(s) && !reservedWords[s] {
+		return s
+	}
+	return `"` + strings.Replace(s, `"`, `""`, -1) + `"`
+}
+
+func quoteName(schema, name string) string {
+	return quoteIdent(schema) + "." + quoteIdent(name)
+}
+
+// quoteQualified quotes a schema qualified name such as 'public.events'.
+func quoteQualified(name string) string {
+	k := strings.Index(name, ".")
+	return quoteName(name[:k], name[k+1:])
+}
+
+func quoteLiteral(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
+
+func commentLiteral(s string) string {
+	if s == "" {
+		return "NULL"
+	}
+	return quoteLiteral(s)
+}
@@ -0,0 +1,175 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import "testing"
+
+func TestDiff(t *testing.T) {
+	type change struct {
+		change, sql string
+	}
+	tests := []struct {
+		name     string
+		from, to string
+		want     []change
+	}{
+		{
+			name: "phases",
+			from: `
+CREATE TABLE public.customers (id int PRIMARY KEY);
+CREATE TABLE public.orders (id int, customer_id int CONSTRAINT orders_customer_fkey REFERENCES public.customers(id));
+`,
+			to: `
+CREATE TYPE public.state AS ENUM ('new');
+CREATE TABLE public.orders (id int, customer_id int, state public.state);
+`,
+			want: []change{
+				{"- foreign key public.orders.orders_customer_fkey", "ALTER TABLE public.orders DROP CONSTRAINT orders_customer_fkey;"},
+				{"- table public.customers", "DROP TABLE public.customers;"},
+				{"+ enum public.state", "CREATE TYPE public.state AS ENUM ('new');"},
+				{"+ column public.orders.state", "ALTER TABLE public.orders ADD COLUMN state public.state;"},
+			},
+		},
+		{
+			name: "foreign keys after the tables they reference",
+			from: `CREATE TABLE public.orders (id int, customer_id int);`,
+			to: `
+CREATE TABLE public.orders (id int, customer_id int CONSTRAINT orders_customer_fkey REFERENCES public.customers(id));
+CREATE TABLE public.customers (id int CONSTRAINT customers_pkey PRIMARY KEY);
+`,
+			want: []change{
+				{"+ table public.customers", "CREATE TABLE public.customers (\n    id int4 NOT NULL,\n    CONSTRAINT customers_pkey PRIMARY KEY (id)\n);"},
+				{"+ foreign key public.orders.orders_customer_fkey", "ALTER TABLE public.orders ADD CONSTRAINT orders_customer_fkey FOREIGN KEY (customer_id) REFERENCES public.customers (id) ON DELETE NO ACTION ON UPDATE NO ACTION;"},
+			},
+		},
+		{
+			name: "serial and identity sequences",
+			from: `CREATE TABLE public.t (id int NOT NULL, b serial);`,
+			to: `
+CREATE TABLE public.t (id int GENERATED BY DEFAULT AS IDENTITY);
+CREATE TABLE public.events (id bigint GENERATED ALWAYS AS IDENTITY, n bigserial);
+`,
+			want: []change{
+				{"- column public.t.b", "ALTER TABLE public.t DROP COLUMN b;"},
+				{"+ table public.events", "CREATE TABLE public.events (\n    id int8 GENERATED ALWAYS AS IDENTITY NOT NULL,\n    n bigserial NOT NULL\n);"},
+				{`~ column public.t.id: identity "" -> "BY DEFAULT"`, "ALTER TABLE public.t\n    ALTER COLUMN id ADD GENERATED BY DEFAULT AS IDENTITY;"},
+			},
+		},
+		{
+			name: "standalone sequences",
+			from: `
+CREATE TABLE public.t (id int);
+CREATE SEQUENCE public.old_seq;
+`,
+			to: `
+CREATE TABLE public.t (id int DEFAULT nextval('public.t_id_seq'::regclass));
+CREATE SEQUENCE public.t_id_seq AS integer;
+ALTER SEQUENCE public.t_id_seq OWNED BY public.t.id;
+`,
+			want: []change{
+				{"+ sequence public.t_id_seq", "CREATE SEQUENCE public.t_id_seq AS int4;"},
+				{`~ column public.t.id: default "" -> "nextval('public.t_id_seq'::regclass)"`, "ALTER TABLE public.t\n    ALTER COLUMN id SET DEFAULT nextval('public.t_id_seq'::regclass);"},
+				{"- sequence public.old_seq", "DROP SEQUENCE public.old_seq;"},
+				{"~ sequence public.t_id_seq: owned by public.t.id", "ALTER SEQUENCE public.t_id_seq OWNED BY public.t.id;"},
+			},
+		},
+		{
+			name: "partitions",
+			from: `
+CREATE TABLE public.m (at date) PARTITION BY RANGE (at);
+CREATE TABLE public.m_2019 PARTITION OF public.m FOR VALUES FROM ('2019-01-01') TO ('2020-01-01');
+CREATE TABLE public.m_2020 (at date);
+`,
+			to: `
+CREATE TABLE public.m (at date) PARTITION BY RANGE (at);
+CREATE TABLE public.m_2019 (at date);
+CREATE TABLE public.m_2020 PARTITION OF public.m FOR VALUES FROM ('2020-01-01') TO ('2021-01-01');
+CREATE TABLE public.m_2021 PARTITION OF public.m FOR VALUES FROM ('2021-01-01') TO ('2022-01-01');
+`,
+			want: []change{
+				{"~ table public.m_2019: partition public.m FOR VALUES FROM ('2019-01-01') TO ('2020-01-01') -> none", "ALTER TABLE public.m DETACH PARTITION public.m_2019;"},
+				{"~ table public.m_2020: partition none -> public.m FOR VALUES FROM ('2020-01-01') TO ('2021-01-01')", "ALTER TABLE public.m ATTACH PARTITION public.m_2020 FOR VALUES FROM ('2020-01-01') TO ('2021-01-01');"},
+				{"+ table public.m_2021", "CREATE TABLE public.m_2021 PARTITION OF public.m FOR VALUES FROM ('2021-01-01') TO ('2022-01-01');"},
+			},
+		},
+		{
+			name: "changes on a partitioned table",
+			from: `
+CREATE TABLE public.orders (id int NOT NULL, note text, at date) PARTITION BY RANGE (at);
+CREATE TABLE public.orders_2024 PARTITION OF public.orders FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+`,
+			to: `
+CREATE TABLE public.orders (id bigint NOT NULL, qty int, at date, CONSTRAINT qty_positive CHECK ((qty > 0))) PARTITION BY RANGE (at);
+CREATE TABLE public.orders_2024 PARTITION OF public.orders FOR VALUES FROM ('2024-01-01') TO ('2025-01-01');
+CREATE INDEX orders_at_idx ON public.orders (at);
+CREATE INDEX orders_2024_at_idx ON public.orders_2024 (at);
+`,
+			want: []change{
+				{"- column public.orders.note", "ALTER TABLE public.orders DROP COLUMN note;"},
+				{"~ column public.orders.id: type int4 -> int8", "ALTER TABLE public.orders\n    ALTER COLUMN id TYPE int8;"},
+				{"+ column public.orders.qty", "ALTER TABLE public.orders ADD COLUMN qty int4;"},
+				{"+ index public.orders.orders_at_idx", "CREATE INDEX orders_at_idx ON public.orders USING btree (at);"},
+				{"+ check public.orders.qty_positive", "ALTER TABLE public.orders ADD CONSTRAINT qty_positive CHECK ((qty > 0));"},
+			},
+		},
+		{
+			name: "enum values",
+			from: `CREATE TYPE public.mood AS ENUM ('happy', 'sad', 'angry');`,
+			to:   `CREATE TYPE public.mood AS ENUM ('meh', 'happy', 'glad', 'sad');`,
+			want: []change{
+				{"- enum value public.mood.angry", "-- enum values can't be removed; recreate public.mood without 'angry'"},
+				{"+ enum value public.mood.meh", "ALTER TYPE public.mood ADD VALUE 'meh' BEFORE 'happy';"},
+				{"+ enum value public.mood.glad", "ALTER TYPE public.mood ADD VALUE 'glad' AFTER 'happy';"},
+			},
+		},
+		{
+			name: "unchanged",
+			from: `
+CREATE TYPE public.mood AS ENUM ('happy');
+CREATE TABLE public.t (id serial PRIMARY KEY, m public.mood);
+`,
+			to: `
+CREATE TYPE public.mood AS ENUM ('happy');
+CREATE TABLE public.t (id serial PRIMARY KEY, m public.mood);
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, err := ParseDDL(tt.from)
+			if err != nil {
+				t.Fatalf("ParseDDL(from): %v", err)
+			}
+			to, err := ParseDDL(tt.to)
+			if err != nil {
+				t.Fatalf("ParseDDL(to): %v", err)
+			}
+			var got []change
+			for _, c := range Diff(from, to) {
+				got = append(got, change{c.String(), c.SQL})
+			}
+			for k := 0; k < len(got) || k < len(tt.want); k++ {
+				switch {
+				case k >= len(got):
+					t.Errorf("missing change %d: %s\n%s", k, tt.want[k].change, tt.want[k].sql)
+				case k >= len(tt.want):
+					t.Errorf("unexpected change %d: %s\n%s", k, got[k].change, got[k].sql)
+				case got[k] != tt.want[k]:
+					t.Errorf("change %d =\n%s\n%s\nwant\n%s\n%s", k, got[k].change, got[k].sql, tt.want[k].change, tt.want[k].sql)
+				}
+			}
+		})
+	}
+}
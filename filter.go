@@ -0,0 +1,212 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Selector chooses the tables, columns, enums, functions and sequences code
+// is generated for.
+//
+// Patterns are globs, e.g. 'schema_*', or regular expressions between
+// slashes, e.g. '/_(old|bak)$/'. Table, enum, function and sequence patterns
+// match either the bare or the schema qualified name, column patterns match
+// 'column', 'table.column' or 'schema.table.column'. An object is selected
+// when no include pattern is given or one matches, and no exclude pattern
+// matches.
+type Selector struct {
+	IncludeTables    []string
+	ExcludeTables    []string
+	IncludeColumns   []string
+	ExcludeColumns   []string
+	IncludeEnums     []string
+	ExcludeEnums     []string
+	IncludeFunctions []string
+	ExcludeFunctions []string
+	IncludeSequences []string
+	ExcludeSequences []string
+	// ChildTables keeps the partitions of partitioned tables, and tables
+	// inheriting from other tables, which are otherwise left out when their
+	// parent is there to generate instead.
+	ChildTables bool
+}
+
+type pattern func(string) bool
+
+func compilePatterns(pp []string) ([]pattern, error) {
+	var r []pattern
+	for _, p := range pp {
+		p := strings.TrimSpace(p)
+		if len(p) > 1 && strings.HasPrefix(p, "/") && strings.HasSuffix(p, "/") {
+			re, err := regexp.Compile(p[1 : len(p)-1])
+			if err != nil {
+				return nil, errors.WithMessage(err, "pattern "+p)
+			}
+			r = append(r, re.MatchString)
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, errors.WithMessage(err, "pattern "+p)
+		}
+		r = append(r, func(s string) bool {
+			ok, _ := path.Match(p, s)
+			return ok
+		})
+	}
+	return r, nil
+}
+
+// selected reports whether one of names is included and none is excluded.
+func selected(include, exclude []pattern, names ...string) bool {
+	match := func(pp []pattern) bool {
+		for _, p := range pp {
+			for _, n := range names {
+				if p(n) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	return (len(include) == 0 || match(include)) && !match(exclude)
+}
+
+// Apply removes from data the tables, columns, enums, functions and sequences
+// s doesn't select.
+// Indexes, foreign keys and sequences of removed columns are removed along
+// with them, and columns of removed enums are generated as text. Primary key
+// columns can't be removed. Unless ChildTables is set, tables whose parent is
+// in data are removed before any pattern applies.
+func (s *Selector) Apply(data *PGData) error {
+	var pats [10][]pattern
+	for k, pp := range [][]string{s.IncludeTables, s.ExcludeTables, s.IncludeColumns, s.ExcludeColumns, s.IncludeEnums, s.ExcludeEnums, s.IncludeFunctions, s.ExcludeFunctions, s.IncludeSequences, s.ExcludeSequences} {
+		p, err := compilePatterns(pp)
+		if err != nil {
+			return err
+		}
+		pats[k] = p
+	}
+
+	var children []string
+	for k, t := range data.Tables {
+		for _, p := range t.Inherits {
+			if _, ok := data.Tables[p]; ok && !s.ChildTables {
+				children = append(children, k)
+				break
+			}
+		}
+	}
+	for _, k := range children {
+		delete(data.Tables, k)
+	}
+	for k, t := range data.Tables {
+		if !selected(pats[0], pats[1], t.Name, t.QualifiedName()) {
+			delete(data.Tables, k)
+		}
+	}
+
+	for _, t := range data.Tables {
+		removed := map[*Column]bool{}
+		var cols []*Column
+		for _, c := range t.Columns {
+			if selected(pats[2], pats[3], c.Name, t.Name+"."+c.Name, t.QualifiedName()+"."+c.Name) {
+				cols = append(cols, c)
+				continue
+			}
+			if c.IsPK {
+				return errors.Errorf("column %s.%s: primary key columns can't be excluded", t.QualifiedName(), c.Name)
+			}
+			removed[c] = true
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		t.Columns = cols
+
+		var idx []*Index
+		for _, ix := range t.Indexes {
+			if !usesColumn(ix.Columns, removed) {
+				idx = append(idx, ix)
+			}
+		}
+		t.Indexes = idx
+
+		var fks []*ForeignKey
+		for _, fk := range t.ForeignKeys {
+			if !usesColumn(fk.Columns, removed) {
+				fks = append(fks, fk)
+			}
+		}
+		t.ForeignKeys = fks
+	}
+
+	for k, en := range data.Enums {
+		if !selected(pats[4], pats[5], en.Name, en.QualifiedName()) {
+			delete(data.Enums, k)
+			registerAsText(en.QualifiedName())
+		}
+	}
+
+	for k, f := range data.Functions {
+		if !selected(pats[6], pats[7], f.Name, f.QualifiedName()) {
+			delete(data.Functions, k)
+		}
+	}
+
+	for k, sq := range data.Sequences {
+		if sq.IsOwned() && sq.owner(data) == nil || !selected(pats[8], pats[9], sq.Name, sq.QualifiedName()) {
+			delete(data.Sequences, k)
+		}
+	}
+	return nil
+}
+
+func usesColumn(cols []*Column, removed map[*Column]bool) bool {
+	for _, c := range cols {
+		if removed[c] {
+			return true
+		}
+	}
+	return false
+}
+
+// registerAsText maps the type name to text, and stops generating it into
+// the model package.
+func registerAsText(name string) {
+	pgToPgxTypeMap[name] = pgToPgxTypeMap["text"]
+	pgToGoTypeMap[name] = pgToGoTypeMap["text"]
+	pgToGoTemplate[name] = pgToGoTemplate["text"]
+	if f, ok := pgStringTemplate["text"]; ok {
+		pgStringTemplate[name] = f
+	} else {
+		delete(pgStringTemplate, name)
+	}
+	if f, ok := goToPgTemplate["text"]; ok {
+		goToPgTemplate[name] = f
+	} else {
+		delete(goToPgTemplate, name)
+	}
+	for k, t := range customTypes {
+		if t == name {
+			customTypes = append(customTypes[:k], customTypes[k+1:]...)
+			break
+		}
+	}
+}
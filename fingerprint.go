@@ -0,0 +1,68 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// CatalogType is the schema qualified name of the column's type as the
+// catalog has it, with domains resolved to their base type, e.g.
+// 'pg_catalog.int4' or 'public._mood'.
+func (c *Column) CatalogType() string {
+	if c.TypeSchema == "" {
+		return "pg_catalog." + c.DataType
+	}
+	return c.TypeSchema + "." + c.DataType
+}
+
+// Fingerprint returns a hash of what the generated code relies on when
+// reading rows: the tables with the names, order, types and nullability of
+// their columns, and the labels of enums. Comments, keys and defaults don't
+// change it.
+func (d *PGData) Fingerprint() string {
+	h := sha256.New()
+	var tables []string
+	for k := range d.Tables {
+		tables = append(tables, k)
+	}
+	sort.Strings(tables)
+	for _, k := range tables {
+		t := d.Tables[k]
+		fmt.Fprintf(h, "table %s\n", t.QualifiedName())
+		for _, c := range t.Columns {
+			fmt.Fprintf(h, "  %s %s %t\n", c.Name, c.CatalogType(), c.Nullable)
+		}
+	}
+	var enums []string
+	for k := range d.Enums {
+		enums = append(enums, k)
+	}
+	sort.Strings(enums)
+	for _, k := range enums {
+		en := d.Enums[k]
+		var vv []string
+		for _, v := range en.Values {
+			vv = append(vv, v.Value)
+		}
+		fmt.Fprintf(h, "enum %s\n", en.QualifiedName())
+		io.WriteString(h, "  "+strings.Join(vv, "\n  ")+"\n")
+	}
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
@@ -0,0 +1,231 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Kinds of routines.
+const (
+	KindFunction  = "function"
+	KindProcedure = "procedure"
+)
+
+// Function is a function or procedure. Args are its input arguments, named
+// 'argN' when they are unnamed. Results are the columns it returns: its OUT
+// arguments or RETURNS TABLE columns, or else a single unnamed column of
+// its return type. Functions returning void have no results.
+type Function struct {
+	Schema  string    `json:"schema"`
+	Name    string    `json:"name"`
+	Kind    string    `json:"kind"`
+	Prefix  string    `json:"-"`
+	Args    []*Column `json:"args,omitempty"`
+	Results []*Column `json:"results,omitempty"`
+	// ReturnsSet is set for functions returning SETOF or TABLE.
+	ReturnsSet bool `json:"returnsSet,omitempty"`
+	// Comment is set with COMMENT ON FUNCTION or PROCEDURE.
+	Comment string `json:"comment,omitempty"`
+}
+
+func (f *Function) QualifiedName() string {
+	return f.Schema + "." + f.Name
+}
+
+// Signature is the qualified name of the function followed by the types of
+// its arguments, e.g. 'public.add(int4,int4)', which tells overloads apart.
+func (f *Function) Signature() string {
+	var tt []string
+	for _, a := range f.Args {
+		tt = append(tt, a.typeKey())
+	}
+	return f.QualifiedName() + "(" + strings.Join(tt, ",") + ")"
+}
+
+// CommentLines returns the comment of the function split in lines, for use
+// in Go comments.
+func (f *Function) CommentLines() []string {
+	return commentLines(f.Comment)
+}
+
+// IsProcedure reports whether f is a procedure, which is run with CALL.
+func (f *Function) IsProcedure() bool {
+	return f.Kind == KindProcedure
+}
+
+// Routine is a function resolved for generating its wrapper.
+type Routine struct {
+	*Function
+	// Name is the name of the wrapper, which is also the prefix of its
+	// result struct.
+	Name string
+	// Table is set when the function returns rows of a table, which are
+	// read into its model. Otherwise a function with more than one result
+	// column returns a struct of them, named Name + "Row".
+	Table *Table
+}
+
+// Row reports whether the results are read into a struct of the columns.
+func (r *Routine) Row() bool {
+	return r.Table == nil && len(r.Results) > 1
+}
+
+// Result is the single result column of a function returning a scalar,
+// composite or array, and nil otherwise.
+func (r *Routine) Result() *Column {
+	if r.Table != nil || len(r.Results) != 1 {
+		return nil
+	}
+	return r.Results[0]
+}
+
+// SQL returns the statement calling the function with its arguments as
+// parameters cast to their types, so overloads resolve. Functions returning
+// rows are selected from, so their columns are read one by one; those of a
+// table by name, as some may not be generated.
+func (r *Routine) SQL() string {
+	var aa []string
+	for k, a := range r.Args {
+		typ := quoteName(a.TypeSchema, a.DataType)
+		if a.Domain != "" {
+			typ = quoteQualified(a.Domain)
+		}
+		aa = append(aa, "$"+strconv.Itoa(k+1)+"::"+typ)
+	}
+	call := quoteName(r.Schema, r.Function.Name) + "(" + strings.Join(aa, ", ") + ")"
+	switch {
+	case r.IsProcedure():
+		return "CALL " + call
+	case r.Table != nil:
+		var cc []string
+		for _, c := range r.Table.Columns {
+			cc = append(cc, quoteIdent(c.Name))
+		}
+		return "SELECT " + strings.Join(cc, ", ") + " FROM " + call
+	case r.Row():
+		return "SELECT * FROM " + call
+	}
+	return "SELECT " + call
+}
+
+// ProcessFunctions resolves the functions in data for generating wrappers,
+// sorted by name. Functions taking or returning types with no Go
+// counterpart, such as triggers, functions returning an untyped record, or
+// taking polymorphic arguments, are skipped. Overloads are told apart by
+// the types of their arguments, and wrappers whose name is taken by a query
+// of qq or another method of the datastore are prefixed with Call.
+func ProcessFunctions(data PGData, qq []Query) []Routine {
+	taken := datastoreMethods(data, qq)
+	overloads := map[string]int{}
+	for _, f := range data.Functions {
+		overloads[f.QualifiedName()]++
+	}
+
+	var rr []Routine
+	for _, f := range data.Functions {
+		r := Routine{Function: f, Name: ExportedName(f.Prefix + f.Name)}
+		if len(f.Results) == 1 && f.Results[0].Name == "" {
+			r.Table = data.Tables[f.Results[0].TypeSchema+"."+f.Results[0].DataType]
+		}
+		if !r.supported() {
+			continue
+		}
+		if overloads[f.QualifiedName()] > 1 {
+			var tt []string
+			for _, a := range f.Args {
+				if strings.HasPrefix(a.DataType, "_") {
+					tt = append(tt, a.DataType[1:]+"_array")
+					continue
+				}
+				tt = append(tt, a.DataType)
+			}
+			r.Name += joinExported(tt)
+		}
+		if taken[r.Name] {
+			r.Name = "Call" + r.Name
+		}
+		r.renameArgs()
+		rr = append(rr, r)
+	}
+	sort.Slice(rr, func(i, j int) bool { return rr[i].Name < rr[j].Name })
+	return rr
+}
+
+// datastoreMethods returns the names of the methods generated on the
+// datastore for data besides function wrappers: the queries of qq, the
+// relation lookups, sequence and materialized view helpers, partition
+// helpers, whether or not they are generated, and VerifySchema.
+func datastoreMethods(data PGData, qq []Query) map[string]bool {
+	taken := map[string]bool{"VerifySchema": true}
+	for _, q := range qq {
+		taken[q.Name] = true
+	}
+	for _, r := range ProcessRelations(data) {
+		taken["Get"+r.ExportedName()] = true
+	}
+	for _, sq := range data.Sequences {
+		for _, m := range []string{"NextVal", "CurrVal", "NextVals"} {
+			taken[m+sq.ExportedName()] = true
+		}
+	}
+	for _, t := range data.Tables {
+		if t.IsMaterializedView() {
+			taken["Refresh"+t.ExportedName()] = true
+		}
+		if t.TimePartitionColumn() != nil {
+			taken["Create"+t.ExportedName()+"Partition"] = true
+			taken["CreateNext"+t.ExportedName()+"Partition"] = true
+		}
+	}
+	return taken
+}
+
+// wrapperNames are the names used in the body of wrappers, besides Go's
+// keywords, which arguments are renamed away from.
+var wrapperNames = map[string]bool{"ctx": true, "st": true, "q": true, "row": true, "rows": true, "r": true, "rr": true, "err": true}
+
+// renameArgs gives r copies of its arguments whose Go names are neither
+// keywords nor taken in the wrapper.
+func (r *Routine) renameArgs() {
+	f := *r.Function
+	f.Args = make([]*Column, len(r.Args))
+	for k, a := range r.Args {
+		c := *a
+		if n := c.GoVar(); wrapperNames[n] || token.IsKeyword(n) {
+			c.Name += "_arg"
+		}
+		f.Args[k] = &c
+	}
+	r.Function = &f
+}
+
+// supported reports whether every argument and result of r maps to a Go
+// type.
+func (r *Routine) supported() bool {
+	cols := r.Args
+	if r.Table == nil {
+		cols = append(cols[:len(cols):len(cols)], r.Results...)
+	}
+	for _, c := range cols {
+		if c.PgxType() == "" {
+			return false
+		}
+	}
+	return true
+}
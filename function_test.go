@@ -0,0 +1,44 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProcessFunctionsNames(t *testing.T) {
+	data, err := ParseDDL(`
+CREATE TABLE public.customers (id int PRIMARY KEY);
+CREATE TABLE public.orders (id int PRIMARY KEY, customer_id int CONSTRAINT customer REFERENCES public.customers(id));
+CREATE SEQUENCE public.tickets AS bigint;
+CREATE FUNCTION public.verify_schema() RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;
+CREATE FUNCTION public.get_orders_customer(id integer) RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;
+CREATE FUNCTION public.next_val_tickets() RETURNS bigint LANGUAGE sql AS $$ SELECT 1::bigint $$;
+CREATE FUNCTION public.get_customers(id integer) RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;
+CREATE FUNCTION public.total(id integer) RETURNS integer LANGUAGE sql AS $$ SELECT 1 $$;
+`)
+	if err != nil {
+		t.Fatalf("ParseDDL: %v", err)
+	}
+	var names []string
+	for _, r := range ProcessFunctions(*data, ProcessQueryDefinitions(QueryDefinitions{}, *data)) {
+		names = append(names, r.Name)
+	}
+	want := []string{"CallGetCustomers", "CallGetOrdersCustomer", "CallNextValTickets", "CallVerifySchema", "Total"}
+	if !reflect.DeepEqual(names, want) {
+		t.Errorf("wrapper names = %v, want %v", names, want)
+	}
+}
@@ -16,10 +16,12 @@ package pgxgen
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 	"unicode"
 
 	"github.com/jackc/pgx"
+	"github.com/jackc/pgx/pgtype"
 	"github.com/pkg/errors"
 	"github.com/reiver/go-stringcase"
 )
@@ -27,6 +29,7 @@ import (
 var pgToPgxTypeMap = map[string]string{
 	"text":        "pgtype.Text",
 	"varchar":     "pgtype.Text",
+	"citext":      "pgtype.Text",
 	"bytea":       "pgtype.Bytea",
 	"int2":        "pgtype.Int2",
 	"int4":        "pgtype.Int4",
@@ -36,16 +39,25 @@ var pgToPgxTypeMap = map[string]string{
 	"_uuid":       "pgtype.UUIDArray",
 	"timestamp":   "pgtype.Timestamp",
 	"timestamptz": "pgtype.Timestamptz",
+	"date":        "pgtype.Date",
+	"time":        "Time",
+	"timetz":      "Timetz",
+	"interval":    "pgtype.Interval",
+	"inet":        "pgtype.Inet",
+	"cidr":        "pgtype.CIDR",
+	"macaddr":     "pgtype.Macaddr",
 	"float4":      "pgtype.Float4",
 	"float8":      "pgtype.Float8",
 	"_float4":     "pgtype.Float4Array",
 	"_float8":     "pgtype.Float8Array",
 	"jsonb":       "pgtype.JSONB",
+	"numeric":     "pgtype.Numeric",
 }
 
 var pgToGoTypeMap = map[string]string{
 	"text":        "string",
 	"varchar":     "string",
+	"citext":      "string",
 	"bytea":       "[]byte",
 	"int2":        "int16",
 	"int4":        "int32",
@@ -55,16 +67,25 @@ var pgToGoTypeMap = map[string]string{
 	"_uuid":       "[]uuid.UUID",
 	"timestamp":   "time.Time",
 	"timestamptz": "time.Time",
+	"date":        "time.Time",
+	"time":        "time.Duration",
+	"timetz":      "time.Time",
+	"interval":    "time.Duration",
+	"inet":        "*net.IPNet",
+	"cidr":        "*net.IPNet",
+	"macaddr":     "net.HardwareAddr",
 	"float4":      "float32",
 	"float8":      "float64",
 	"_float4":     "[]float32",
 	"_float8":     "[]float64",
 	"jsonb":       "[]byte",
+	"numeric":     "*big.Rat",
 }
 
 var pgToGoTemplate = map[string]func(v, p string) string{
 	"text":        func(v, p string) string { return fmt.Sprintf("%s.%s.String", v, p) },
 	"varchar":     func(v, p string) string { return fmt.Sprintf("%s.%s.String", v, p) },
+	"citext":      func(v, p string) string { return fmt.Sprintf("%s.%s.String", v, p) },
 	"bytea":       func(v, p string) string { return fmt.Sprintf("%s.%s.Bytes", v, p) },
 	"int2":        func(v, p string) string { return fmt.Sprintf("%s.%s.Int", v, p) },
 	"int4":        func(v, p string) string { return fmt.Sprintf("%s.%s.Int", v, p) },
@@ -74,16 +95,24 @@ var pgToGoTemplate = map[string]func(v, p string) string{
 	"_uuid":       func(v, p string) string { return fmt.Sprintf("ToUUIDSlice(%s.%s)", v, p) },
 	"timestamp":   func(v, p string) string { return fmt.Sprintf("%s.%s.Time", v, p) },
 	"timestamptz": func(v, p string) string { return fmt.Sprintf("%s.%s.Time", v, p) },
+	"date":        func(v, p string) string { return fmt.Sprintf("%s.%s.Time", v, p) },
+	"time":        func(v, p string) string { return fmt.Sprintf("%s.%s.Duration()", v, p) },
+	"timetz":      func(v, p string) string { return fmt.Sprintf("%s.%s.Time()", v, p) },
+	"inet":        func(v, p string) string { return fmt.Sprintf("IPNet(%s.%s)", v, p) },
+	"cidr":        func(v, p string) string { return fmt.Sprintf("CIDRNet(%s.%s)", v, p) },
+	"macaddr":     func(v, p string) string { return fmt.Sprintf("%s.%s.Addr", v, p) },
 	"float4":      func(v, p string) string { return fmt.Sprintf("%s.%s.Float", v, p) },
 	"float8":      func(v, p string) string { return fmt.Sprintf("%s.%s.Float", v, p) },
 	"_float8":     func(v, p string) string { return fmt.Sprintf("ToFloat64Slice(%s.%s)", v, p) },
 	"_float4":     func(v, p string) string { return fmt.Sprintf("ToFloat32Slice(%s.%s)", v, p) },
 	"jsonb":       func(v, p string) string { return fmt.Sprintf("%s.%s.Bytes", v, p) },
+	"numeric":     func(v, p string) string { return fmt.Sprintf("Rat(%s.%s)", v, p) },
 }
 
 var pgStringTemplate = map[string]func(v...interface{}) string{
 	"text":        func(v ...interface{}) string { return fmt.Sprintf("%s.TextToString(%s)", v...) },
 	"varchar":     func(v ...interface{}) string { return fmt.Sprintf("%s.VarcharToString(%s)", v...) },
+	"citext":      func(v ...interface{}) string { return fmt.Sprintf("%s.TextToString(%s)", v...) },
 	"bytea":       func(v ...interface{}) string { return fmt.Sprintf("%s.ByteaToString(%s)", v...) },
 	"int2":        func(v ...interface{}) string { return fmt.Sprintf("%s.Int2ToString(%s)", v...) },
 	"int4":        func(v ...interface{}) string { return fmt.Sprintf("%s.Int4ToString(%s)", v...) },
@@ -92,14 +121,23 @@ var pgStringTemplate = map[string]func(v...interface{}) string{
 	"uuid":        func(v ...interface{}) string { return fmt.Sprintf("%s.UUIDToString(%s)", v...) },
 	"timestamp":   func(v ...interface{}) string { return fmt.Sprintf("%s.TimestampToString(%s)", v...) },
 	"timestamptz": func(v ...interface{}) string { return fmt.Sprintf("%s.TimestamptzToString(%s)", v...) },
+	"date":        func(v ...interface{}) string { return fmt.Sprintf("%s.DateToString(%s)", v...) },
+	"time":        func(v ...interface{}) string { return fmt.Sprintf("%s.TimeToString(%s)", v...) },
+	"timetz":      func(v ...interface{}) string { return fmt.Sprintf("%s.TimetzToString(%s)", v...) },
+	"interval":    func(v ...interface{}) string { return fmt.Sprintf("%s.IntervalToString(%s)", v...) },
+	"inet":        func(v ...interface{}) string { return fmt.Sprintf("%s.InetToString(%s)", v...) },
+	"cidr":        func(v ...interface{}) string { return fmt.Sprintf("%s.CIDRToString(%s)", v...) },
+	"macaddr":     func(v ...interface{}) string { return fmt.Sprintf("%s.MacaddrToString(%s)", v...) },
 	"float4":      func(v ...interface{}) string { return fmt.Sprintf("%s.Float4ToString(%s)", v...) },
 	"float8":      func(v ...interface{}) string { return fmt.Sprintf("%s.Float8ToString(%s)", v...) },
+	"numeric":     func(v ...interface{}) string { return fmt.Sprintf("%s.NumericToString(%s)", v...) },
 }
 
 
 var goToPgTemplate = map[string]func(v string) string{
 	"text":        func(v string) string { return fmt.Sprintf("pgtype.Text{String: %s, Status: pgtype.Present}", v) },
 	"varchar":     func(v string) string { return fmt.Sprintf("pgtype.Text{String: %s, Status: pgtype.Present}", v) },
+	"citext":      func(v string) string { return fmt.Sprintf("pgtype.Text{String: %s, Status: pgtype.Present}", v) },
 	"bytea":       func(v string) string { return fmt.Sprintf("pgtype.Bytea{Bytes: %s, Status: pgtype.Present}", v) },
 	"int2":        func(v string) string { return fmt.Sprintf("pgtype.Int2{Int: %s, Status: pgtype.Present}", v) },
 	"int4":        func(v string) string { return fmt.Sprintf("pgtype.Int4{Int: %s, Status: pgtype.Present}", v) },
@@ -109,11 +147,19 @@ var goToPgTemplate = map[string]func(v string) string{
 	"_uuid":       func(v string) string { return fmt.Sprintf("UUIDArray(%s)", v) },
 	"timestamp":   func(v string) string { return fmt.Sprintf("pgtype.Timestamp{Time: %s, Status: pgtype.Present}", v) },
 	"timestamptz": func(v string) string { return fmt.Sprintf("pgtype.Timestamp{Time: %s, Status: pgtype.Present}", v) },
+	"date":        func(v string) string { return fmt.Sprintf("pgtype.Date{Time: %s, Status: pgtype.Present}", v) },
+	"time":        func(v string) string { return fmt.Sprintf("NewTime(%s)", v) },
+	"timetz":      func(v string) string { return fmt.Sprintf("NewTimetz(%s)", v) },
+	"interval":    func(v string) string { return fmt.Sprintf("NewInterval(%s)", v) },
+	"inet":        func(v string) string { return fmt.Sprintf("NewInet(%s)", v) },
+	"cidr":        func(v string) string { return fmt.Sprintf("NewCIDR(%s)", v) },
+	"macaddr":     func(v string) string { return fmt.Sprintf("NewMacaddr(%s)", v) },
 	"float4":      func(v string) string { return fmt.Sprintf("pgtype.Float4{Float: %s, Status: pgtype.Present}", v) },
 	"float8":      func(v string) string { return fmt.Sprintf("pgtype.Float8{Float: %s, Status: pgtype.Present}", v) },
 	"_float8":     func(v string) string { return fmt.Sprintf("Float8Array(%s)", v) },
 	"_float4":     func(v string) string { return fmt.Sprintf("Float4Array(%s)", v) },
 	"jsonb":       func(v string) string { return fmt.Sprintf("pgtype.JSONB{Bytes: %s, Status: pgtype.Present}", v) },
+	"numeric":     func(v string) string { return fmt.Sprintf("NewNumericFromRat(%s)", v) },
 }
 
 var recognizedAcronyms = map[string]string{
@@ -123,7 +169,10 @@ var recognizedAcronyms = map[string]string{
 	"Fb":  "FB",
 }
 
-var customEnumType = []string{}
+// customTypes lists the type map keys of types generated into the model
+// package, i.e. enums and composites, and time and timetz, which pgtype has
+// no type for.
+var customTypes = []string{"time", "timetz"}
 
 func replaceAcronyms(s string) string {
 	for k, v := range recognizedAcronyms {
@@ -147,12 +196,18 @@ func shortName(s string) string {
 }
 
 type Enum struct {
-	Name   string
-	Values []*EnumValue
+	Schema string       `json:"schema"`
+	Name   string       `json:"name"`
+	Prefix string       `json:"-"`
+	Values []*EnumValue `json:"values"`
+}
+
+func (e *Enum) QualifiedName() string {
+	return e.Schema + "." + e.Name
 }
 
 func (e *Enum) ExportedName() string {
-	return ExportedName(e.Name)
+	return ExportedName(e.Prefix + e.Name)
 }
 
 func (e *Enum) ShortName() string {
@@ -160,15 +215,88 @@ func (e *Enum) ShortName() string {
 }
 
 func (e *Enum) PgxType() string {
-	return pgToPgxTypeMap[e.Name]
+	return pgToPgxTypeMap[e.QualifiedName()]
 }
 
 func (e *Enum) GoType() string {
-	return pgToGoTypeMap[e.Name]
+	return pgToGoTypeMap[e.QualifiedName()]
+}
+
+// Composite is a user defined composite type. Its attributes are described
+// as columns, in attribute order.
+type Composite struct {
+	Schema     string    `json:"schema"`
+	Name       string    `json:"name"`
+	Prefix     string    `json:"-"`
+	Attributes []*Column `json:"attributes"`
+}
+
+func (c *Composite) QualifiedName() string {
+	return c.Schema + "." + c.Name
+}
+
+func (c *Composite) ExportedName() string {
+	return ExportedName(c.Prefix + c.Name)
+}
+
+func (c *Composite) ShortName() string {
+	return shortName(replaceAcronyms(stringcase.ToPascalCase(c.Name)))
+}
+
+func (c *Composite) PgxType() string {
+	return pgToPgxTypeMap[c.QualifiedName()]
+}
+
+func (c *Composite) GoType() string {
+	return pgToGoTypeMap[c.QualifiedName()]
+}
+
+// Domain is a user defined domain. BaseType and BaseTypeSchema name the
+// underlying non-domain type, resolved through domains over domains.
+type Domain struct {
+	Schema         string   `json:"schema"`
+	Name           string   `json:"name"`
+	Prefix         string   `json:"-"`
+	BaseType       string   `json:"baseType"`
+	BaseTypeSchema string   `json:"baseTypeSchema"`
+	NotNull        bool     `json:"notNull,omitempty"`
+	Default        string   `json:"default,omitempty"`
+	Checks         []string `json:"checks,omitempty"`
+}
+
+func (d *Domain) QualifiedName() string {
+	return d.Schema + "." + d.Name
+}
+
+func (d *Domain) ExportedName() string {
+	return ExportedName(d.Prefix + d.Name)
+}
+
+func (d *Domain) ShortName() string {
+	return shortName(replaceAcronyms(stringcase.ToPascalCase(d.Name)))
+}
+
+// Base describes the base type as a column, for looking up its types.
+func (d *Domain) Base() *Column {
+	return &Column{DataType: d.BaseType, TypeSchema: d.BaseTypeSchema}
+}
+
+// HasGoType reports whether a distinct Go type is generated for the domain.
+func (d *Domain) HasGoType() bool {
+	_, ok := pgToPgxTypeMap[d.QualifiedName()]
+	return ok
+}
+
+func (d *Domain) PgxType() string {
+	return pgToPgxTypeMap[d.QualifiedName()]
+}
+
+func (d *Domain) GoType() string {
+	return pgToGoTypeMap[d.QualifiedName()]
 }
 
 type EnumValue struct {
-	Value string
+	Value string `json:"value"`
 }
 
 func (e *EnumValue) ExportedName() string {
@@ -179,17 +307,108 @@ func (e *EnumValue) GoType() string {
 	return pgToGoTypeMap[e.Value]
 }
 
+// Kinds of relations inspected as tables.
+const (
+	KindTable            = "table"
+	KindView             = "view"
+	KindMaterializedView = "materialized view"
+	KindForeignTable     = "foreign table"
+)
+
 type Table struct {
-	Catalog     string
-	Schema      string
-	Name        string
-	Columns     []*Column
-	PrimaryKeys []*Column
-	Indexes     map[string][]*Column
+	Catalog     string        `json:"catalog,omitempty"`
+	Schema      string        `json:"schema"`
+	Name        string        `json:"name"`
+	Kind        string        `json:"kind"`
+	Prefix      string        `json:"-"`
+	Columns     []*Column     `json:"columns"`
+	PrimaryKeys []*Column     `json:"-"`
+	Indexes     []*Index      `json:"indexes,omitempty"`
+	ForeignKeys []*ForeignKey `json:"foreignKeys,omitempty"`
+	Checks      []*Check      `json:"checks,omitempty"`
+	// Comment is set with COMMENT ON TABLE.
+	Comment string `json:"comment,omitempty"`
+	// Inherits holds the qualified names of the tables this one inherits
+	// from, in order, or of the table it is a partition of.
+	Inherits []string `json:"inherits,omitempty"`
+	// PartitionKey is set on partitioned tables, e.g. 'RANGE (created_at)'.
+	PartitionKey string `json:"partitionKey,omitempty"`
+	// PartitionBound is set on partitions, e.g.
+	// "FOR VALUES FROM ('2024-01-01') TO ('2024-02-01')" or 'DEFAULT'.
+	PartitionBound string `json:"partitionBound,omitempty"`
+}
+
+// CommentLines returns the comment of the table split in lines, for use in
+// Go comments.
+func (t *Table) CommentLines() []string {
+	return commentLines(t.Comment)
+}
+
+func commentLines(s string) []string {
+	s = strings.TrimSpace(strings.Replace(s, "\r\n", "\n", -1))
+	if s == "" {
+		return nil
+	}
+	lines := strings.Split(s, "\n")
+	for k := range lines {
+		lines[k] = strings.TrimRightFunc(lines[k], unicode.IsSpace)
+	}
+	return lines
+}
+
+func (t *Table) IsView() bool {
+	return t.Kind == KindView
+}
+
+func (t *Table) IsMaterializedView() bool {
+	return t.Kind == KindMaterializedView
+}
+
+// IsReadOnly reports whether rows can't be written to the relation directly.
+func (t *Table) IsReadOnly() bool {
+	return t.IsView() || t.IsMaterializedView()
+}
+
+// IsPartitioned reports whether the table is partitioned, so its rows are
+// kept in its partitions.
+func (t *Table) IsPartitioned() bool {
+	return t.PartitionKey != ""
+}
+
+// IsChild reports whether the table is a partition, or inherits from
+// another table.
+func (t *Table) IsChild() bool {
+	return len(t.Inherits) > 0
+}
+
+// TimePartitionColumn returns the column of a table partitioned by range of
+// a single date or timestamp column, and nil for other tables.
+func (t *Table) TimePartitionColumn() *Column {
+	key := t.PartitionKey
+	if !strings.HasPrefix(strings.ToUpper(key), "RANGE (") || !strings.HasSuffix(key, ")") {
+		return nil
+	}
+	name := strings.TrimSpace(key[len("RANGE (") : len(key)-1])
+	if len(name) > 1 && name[0] == '"' && name[len(name)-1] == '"' {
+		name = strings.Replace(name[1:len(name)-1], `""`, `"`, -1)
+	}
+	c := t.Column(name)
+	if c == nil || c.TypeSchema != "pg_catalog" {
+		return nil
+	}
+	switch c.DataType {
+	case "date", "timestamp", "timestamptz":
+		return c
+	}
+	return nil
+}
+
+func (t *Table) QualifiedName() string {
+	return t.Schema + "." + t.Name
 }
 
 func (t *Table) ExportedName() string {
-	return ExportedName(t.Name)
+	return ExportedName(t.Prefix + t.Name)
 }
 
 func (t *Table) ShortName() string {
@@ -200,12 +419,177 @@ func (t *Table) GoType() string {
 	return t.ExportedName()
 }
 
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c
+		}
+	}
+	return nil
+}
+
+// Index is an index on a table. Keys holds the definition of every key
+// column in index order; Columns holds the matching table column, or nil
+// where the key is an expression. INCLUDE columns are not listed.
+type Index struct {
+	Name      string    `json:"name"`
+	Method    string    `json:"method"`
+	IsUnique  bool      `json:"unique,omitempty"`
+	IsPrimary bool      `json:"primary,omitempty"`
+	Predicate string    `json:"predicate,omitempty"`
+	Keys      []string  `json:"keys"`
+	Columns   []*Column `json:"-"`
+}
+
+func (i *Index) IsPartial() bool {
+	return i.Predicate != ""
+}
+
+func (i *Index) hasColumn(c *Column) bool {
+	for _, ic := range i.Columns {
+		if ic == c {
+			return true
+		}
+	}
+	return false
+}
+
+func (i *Index) HasExpressions() bool {
+	for _, c := range i.Columns {
+		if c == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ForeignKey is a foreign key constraint declared on a table. Columns and
+// RefColumns are listed in constraint order, so Columns[i] references
+// RefColumns[i].
+type ForeignKey struct {
+	Name       string    `json:"name"`
+	Columns    []*Column `json:"-"`
+	RefSchema  string    `json:"refSchema"`
+	RefTable   string    `json:"refTable"`
+	RefColumns []string  `json:"refColumns"`
+	OnDelete   string    `json:"onDelete"`
+	OnUpdate   string    `json:"onUpdate"`
+}
+
+// ExportedName names the relation from the referencing table's point of view,
+// e.g. 'customer' for orders.customer_id.
+func (fk *ForeignKey) ExportedName() string {
+	if len(fk.Columns) == 1 && strings.HasSuffix(fk.Columns[0].Name, "_id") {
+		return ExportedName(strings.TrimSuffix(fk.Columns[0].Name, "_id"))
+	}
+	n := ExportedName(fk.RefTable) + "By"
+	for _, c := range fk.Columns {
+		n += c.ExportedName()
+	}
+	return n
+}
+
 type Column struct {
-	Position int
-	Nullable bool
-	Name     string
-	DataType string
-	IsPK     bool
+	Position   int    `json:"position"`
+	Nullable   bool   `json:"nullable"`
+	Name       string `json:"name"`
+	DataType   string `json:"dataType"`
+	TypeSchema string `json:"typeSchema"`
+	Domain     string `json:"domain,omitempty"`
+	IsPK       bool   `json:"-"`
+	// Precision and Scale are those declared for numeric columns, and 0
+	// for numeric columns of any precision.
+	Precision int `json:"precision,omitempty"`
+	Scale     int `json:"scale,omitempty"`
+	// Default is the default expression of the column, Identity is either
+	// 'ALWAYS' or 'BY DEFAULT' for identity columns, and Generated is the
+	// expression of a generated column.
+	Default   string `json:"default,omitempty"`
+	Identity  string `json:"identity,omitempty"`
+	Generated string `json:"generated,omitempty"`
+	// Comment is set with COMMENT ON COLUMN.
+	Comment string `json:"comment,omitempty"`
+}
+
+// CommentLines returns the comment of the column split in lines, for use in
+// Go comments.
+func (c *Column) CommentLines() []string {
+	return commentLines(c.Comment)
+}
+
+// Modifier returns the precision and scale of a numeric column as declared,
+// e.g. '(10,2)', or "" if it has none.
+func (c *Column) Modifier() string {
+	switch {
+	case c.Precision == 0:
+		return ""
+	case c.Scale == 0:
+		return fmt.Sprintf("(%d)", c.Precision)
+	}
+	return fmt.Sprintf("(%d,%d)", c.Precision, c.Scale)
+}
+
+// IsSerial reports whether the column defaults to the next value of a
+// sequence, as serial columns do.
+func (c *Column) IsSerial() bool {
+	return strings.HasPrefix(c.Default, "nextval(")
+}
+
+// IsReadOnly reports whether the server rejects values for the column, as it
+// does for generated columns and identity columns generated always.
+func (c *Column) IsReadOnly() bool {
+	return c.Generated != "" || c.Identity == "ALWAYS"
+}
+
+// ServerDefault describes the value the server fills in when the column is
+// omitted from an insert, or is empty if there is none. It is on one line,
+// for use in line comments, even when the expression spans several.
+func (c *Column) ServerDefault() string {
+	switch {
+	case c.Generated != "":
+		return "generated always as " + oneLine(c.Generated)
+	case c.Identity != "":
+		return "identity, generated " + strings.ToLower(c.Identity)
+	case c.IsSerial():
+		return "serial, " + oneLine(c.Default)
+	}
+	return oneLine(c.Default)
+}
+
+// oneLine joins the lines of s with spaces, dropping their indentation.
+func oneLine(s string) string {
+	lines := commentLines(s)
+	for k := range lines {
+		lines[k] = strings.TrimSpace(lines[k])
+	}
+	return strings.Join(lines, " ")
+}
+
+// extensionTypes are the types of extensions with a Go mapping. They are
+// keyed by name like built-in types, whichever schema the extension was
+// created in.
+var extensionTypes = map[string]bool{"citext": true}
+
+// typeKey is the key of the column's type in the type maps. Built-in types
+// are keyed by name, user defined types by their schema qualified name.
+// Columns of a domain are keyed by their base type, unless the domain has
+// its own Go type.
+func (c *Column) typeKey() string {
+	if _, ok := pgToPgxTypeMap[c.Domain]; ok && c.Domain != "" {
+		return c.Domain
+	}
+	if c.TypeSchema == "" || c.TypeSchema == "pg_catalog" || extensionTypes[c.DataType] {
+		return c.DataType
+	}
+	return c.TypeSchema + "." + c.DataType
+}
+
+// elemTypeKey is the type map key of the element type of an array column.
+func (c *Column) elemTypeKey() string {
+	if !strings.HasPrefix(c.DataType, "_") {
+		return c.typeKey()
+	}
+	return (&Column{DataType: c.DataType[1:], TypeSchema: c.TypeSchema}).typeKey()
 }
 
 func (c *Column) ExportedName() string {
@@ -217,29 +601,32 @@ func (c *Column) ShortName() string {
 }
 
 func (c *Column) PgxType() string {
-	return pgToPgxTypeMap[c.DataType]
+	return pgToPgxTypeMap[c.typeKey()]
 }
 
 func (c *Column) GoType() string {
-	return pgToGoTypeMap[c.DataType]
+	return pgToGoTypeMap[c.typeKey()]
 }
 
 func (c *Column) QualifiedPgxType(s string) string {
-	for _, t := range customEnumType {
-		if c.DataType == t {
-			return s + "." + pgToPgxTypeMap[c.DataType]
+	for _, t := range customTypes {
+		if c.typeKey() == t {
+			return s + "." + pgToPgxTypeMap[c.typeKey()]
 		}
 	}
-	return pgToPgxTypeMap[c.DataType]
+	return pgToPgxTypeMap[c.typeKey()]
 }
 
+// QualifiedGoType returns the Go type of the column, prefixed with the model
+// package s when it is generated there. Go types of other packages, such as
+// the time.Duration of time columns, are left alone.
 func (c *Column) QualifiedGoType(s string) string {
-	for _, t := range customEnumType {
-		if c.DataType == t {
-			return s + "." + pgToGoTypeMap[c.DataType]
+	for _, t := range customTypes {
+		if c.typeKey() == t && !strings.Contains(pgToGoTypeMap[t], ".") {
+			return s + "." + pgToGoTypeMap[c.typeKey()]
 		}
 	}
-	return pgToGoTypeMap[c.DataType]
+	return pgToGoTypeMap[c.typeKey()]
 }
 
 func (c *Column) GoVar() string {
@@ -247,20 +634,27 @@ func (c *Column) GoVar() string {
 }
 
 func (c *Column) GoVarTemplate() string {
-	//for _, t := range customEnumType {
-	//	if c.DataType == t {
+	//for _, t := range customTypes {
+	//	if c.typeKey() == t {
 	//		return "" + replaceAcronyms(stringcase.ToCamelCase(c.Name)) + ".String"
 	//	}
 	//}
 	return replaceAcronyms(stringcase.ToCamelCase(c.Name))
 }
 
+// GoValueTemplate returns the expression converting field c of v to its Go
+// type, or "" for types whose conversion can fail, such as interval, which
+// converts with the two-value Duration.
 func (c *Column) GoValueTemplate(v string) string {
-	return pgToGoTemplate[c.DataType](v, c.ExportedName())
+	f, ok := pgToGoTemplate[c.typeKey()]
+	if !ok {
+		return ""
+	}
+	return f(v, c.ExportedName())
 }
 
 func (c *Column) PgStringTemplate(v...interface{}) string {
-	f, ok := pgStringTemplate[c.DataType]
+	f, ok := pgStringTemplate[c.typeKey()]
 	if !ok {
 		return "PG_STRING_TEMPLATE"
 	}
@@ -268,91 +662,693 @@ func (c *Column) PgStringTemplate(v...interface{}) string {
 }
 
 func (c *Column) PgValueTemplate(v string) string {
-	return goToPgTemplate[c.DataType](v)
+	return goToPgTemplate[c.typeKey()](v)
 }
 
+// PGData holds the inspected enums and tables, keyed by their schema
+// qualified names. Functions are keyed by their signature.
 type PGData struct {
-	Enums  map[string]*Enum
-	Tables map[string]*Table
+	Enums      map[string]*Enum      `json:"enums"`
+	Composites map[string]*Composite `json:"composites"`
+	Domains    map[string]*Domain    `json:"domains"`
+	Tables     map[string]*Table     `json:"tables"`
+	Functions  map[string]*Function  `json:"functions,omitempty"`
+	Sequences  map[string]*Sequence  `json:"sequences,omitempty"`
+}
+
+// Table looks up a table by its qualified name, or by its bare name when
+// that is unambiguous.
+func (d *PGData) Table(name string) *Table {
+	if t, ok := d.Tables[name]; ok {
+		return t
+	}
+	var found *Table
+	for _, t := range d.Tables {
+		if t.Name != name {
+			continue
+		}
+		if found != nil {
+			return nil
+		}
+		found = t
+	}
+	return found
+}
+
+// Uses reports whether a column, attribute, argument or result in d is of
+// one of the types, given as type map keys such as 'time'.
+func (d *PGData) Uses(types ...string) bool {
+	uses := func(cols []*Column) bool {
+		for _, c := range cols {
+			for _, t := range types {
+				if c.typeKey() == t {
+					return true
+				}
+			}
+		}
+		return false
+	}
+	for _, t := range d.Tables {
+		if uses(t.Columns) {
+			return true
+		}
+	}
+	for _, c := range d.Composites {
+		if uses(c.Attributes) {
+			return true
+		}
+	}
+	for _, f := range d.Functions {
+		if uses(f.Args) || uses(f.Results) {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckTypes returns an error naming the first column or composite
+// attribute, by name, whose type has no Go mapping, which would otherwise
+// be generated as a field without a type.
+func (d *PGData) CheckTypes() error {
+	var unmapped []string
+	check := func(owner string, cols []*Column) {
+		for _, c := range cols {
+			if c.PgxType() == "" {
+				typ := c.typeKey()
+				if c.Domain != "" {
+					typ = c.Domain + " over " + typ
+				}
+				unmapped = append(unmapped, fmt.Sprintf("column %s.%s: type %s has no Go mapping; exclude the column to generate the rest", owner, c.Name, typ))
+			}
+		}
+	}
+	for _, t := range d.Tables {
+		check(t.QualifiedName(), t.Columns)
+	}
+	for _, ct := range d.Composites {
+		check(ct.QualifiedName(), ct.Attributes)
+	}
+	if len(unmapped) == 0 {
+		return nil
+	}
+	sort.Strings(unmapped)
+	return errors.New(unmapped[0])
+}
+
+// SortedTables returns the tables of d sorted by their qualified name, so
+// code derived from them is generated the same way every time.
+func (d *PGData) SortedTables() []*Table {
+	tt := make([]*Table, 0, len(d.Tables))
+	for _, t := range d.Tables {
+		tt = append(tt, t)
+	}
+	sort.Slice(tt, func(i, j int) bool { return tt[i].QualifiedName() < tt[j].QualifiedName() })
+	return tt
+}
+
+// Schemas returns the sorted names of all schemas holding a table, type,
+// function or sequence.
+func (d *PGData) Schemas() []string {
+	seen := map[string]bool{}
+	for _, t := range d.Tables {
+		seen[t.Schema] = true
+	}
+	for _, en := range d.Enums {
+		seen[en.Schema] = true
+	}
+	for _, c := range d.Composites {
+		seen[c.Schema] = true
+	}
+	for _, dom := range d.Domains {
+		seen[dom.Schema] = true
+	}
+	for _, f := range d.Functions {
+		seen[f.Schema] = true
+	}
+	for _, sq := range d.Sequences {
+		seen[sq.Schema] = true
+	}
+	var ss []string
+	for s := range seen {
+		ss = append(ss, s)
+	}
+	sort.Strings(ss)
+	return ss
+}
+
+// Schema returns the subset of d belonging to schema. Enums, composites and
+// domains of other schemas used by its tables are included so the subset can
+// be generated on its own, with their Go names prefixed by their schema so
+// they don't collide with the types of schema. They are copies, which
+// RegisterTypes maps columns to before the subset is generated.
+func (d *PGData) Schema(schema string) *PGData {
+	sub := &PGData{Enums: map[string]*Enum{}, Composites: map[string]*Composite{}, Domains: map[string]*Domain{}, Tables: map[string]*Table{}}
+	var use func(cols []*Column)
+	use = func(cols []*Column) {
+		for _, c := range cols {
+			if dom, ok := d.Domains[c.Domain]; ok {
+				sub.Domains[c.Domain] = dom
+			}
+			k := c.elemTypeKey()
+			if en, ok := d.Enums[k]; ok {
+				sub.Enums[k] = en
+			}
+			if ct, ok := d.Composites[k]; ok && sub.Composites[k] == nil {
+				sub.Composites[k] = ct
+				use(ct.Attributes)
+			}
+		}
+	}
+	for k, t := range d.Tables {
+		if t.Schema != schema {
+			continue
+		}
+		sub.Tables[k] = t
+		use(t.Columns)
+	}
+	for k, en := range d.Enums {
+		if en.Schema == schema {
+			sub.Enums[k] = en
+		}
+	}
+	for k, ct := range d.Composites {
+		if ct.Schema == schema && sub.Composites[k] == nil {
+			sub.Composites[k] = ct
+			use(ct.Attributes)
+		}
+	}
+	for k, dom := range d.Domains {
+		if dom.Schema == schema {
+			sub.Domains[k] = dom
+		}
+	}
+	for k, f := range d.Functions {
+		if f.Schema != schema {
+			continue
+		}
+		if sub.Functions == nil {
+			sub.Functions = map[string]*Function{}
+		}
+		sub.Functions[k] = f
+		use(f.Args)
+		use(f.Results)
+	}
+	for k, sq := range d.Sequences {
+		if sq.Schema != schema {
+			continue
+		}
+		if sub.Sequences == nil {
+			sub.Sequences = map[string]*Sequence{}
+		}
+		sub.Sequences[k] = sq
+	}
+
+	// The types are copied, as the same type is named differently in the
+	// package of its own schema.
+	prefix := func(s string) string {
+		if s == schema {
+			return ""
+		}
+		return s + "_"
+	}
+	for k, en := range sub.Enums {
+		cp := *en
+		cp.Prefix = prefix(cp.Schema)
+		sub.Enums[k] = &cp
+	}
+	for k, ct := range sub.Composites {
+		cp := *ct
+		cp.Prefix = prefix(cp.Schema)
+		sub.Composites[k] = &cp
+	}
+	for k, dom := range sub.Domains {
+		cp := *dom
+		cp.Prefix = prefix(cp.Schema)
+		sub.Domains[k] = &cp
+	}
+	return sub
+}
+
+// RegisterTypes maps columns of the enums, composites and domains of data to
+// their Go types under the names they have in data, such as the prefixed
+// names of the types of other schemas in a subset returned by Schema. The
+// mapping is global, so it applies until the types are registered again.
+// Domains are only registered if they map to a distinct Go type.
+func RegisterTypes(data *PGData) {
+	for _, en := range data.Enums {
+		registerEnum(en)
+	}
+	for _, c := range data.Composites {
+		registerComposite(c)
+	}
+	for _, d := range data.Domains {
+		if d.HasGoType() {
+			registerDomain(d)
+		}
+	}
+}
+
+// PrefixSchemas prefixes the Go names of all tables and types outside of
+// schema with the name of their schema, so that same named tables of
+// different schemas may be generated into a single package.
+func PrefixSchemas(data *PGData, schema string) {
+	for _, t := range data.Tables {
+		if t.Schema != schema {
+			t.Prefix = t.Schema + "_"
+		}
+	}
+	for _, en := range data.Enums {
+		if en.Schema != schema {
+			en.Prefix = en.Schema + "_"
+			registerEnum(en)
+		}
+	}
+	for _, c := range data.Composites {
+		if c.Schema != schema {
+			c.Prefix = c.Schema + "_"
+			registerComposite(c)
+		}
+	}
+	for _, d := range data.Domains {
+		if d.Schema != schema {
+			d.Prefix = d.Schema + "_"
+			if d.HasGoType() {
+				registerDomain(d)
+			}
+		}
+	}
+	for _, f := range data.Functions {
+		if f.Schema != schema {
+			f.Prefix = f.Schema + "_"
+		}
+	}
+	for _, sq := range data.Sequences {
+		if sq.Schema != schema {
+			sq.Prefix = sq.Schema + "_"
+		}
+	}
+}
+
+// RegisterDomainTypes maps columns of the domains in data to a distinct Go
+// type per domain, instead of to the Go type of the domain's base type.
+// Domains over types without a pgtype counterpart keep mapping to their base.
+func RegisterDomainTypes(data *PGData) {
+	for _, d := range data.Domains {
+		if strings.HasPrefix(d.Base().PgxType(), "pgtype.") {
+			registerDomain(d)
+		}
+	}
+}
+
+func registerDomain(d *Domain) {
+	name, base := d.QualifiedName(), d.Base()
+	basePgx := base.PgxType()
+	t := d.ExportedName()
+	pgToPgxTypeMap[name] = t
+	pgToGoTypeMap[name] = base.GoType()
+	if f, ok := pgToGoTemplate[base.typeKey()]; ok {
+		pgToGoTemplate[name] = f
+	}
+	if f, ok := pgStringTemplate[base.typeKey()]; ok {
+		pgStringTemplate[name] = func(v ...interface{}) string {
+			return f(v[0], fmt.Sprintf("%s(%s)", basePgx, v[1]))
+		}
+	}
+	if f, ok := goToPgTemplate[base.typeKey()]; ok {
+		goToPgTemplate[name] = func(v string) string { return fmt.Sprintf("%s(%s)", t, f(v)) }
+	}
+	addCustomType(name)
 }
 
-func Inspect(conn *pgx.Conn, schema string) (*PGData, error) {
+func addCustomType(name string) {
+	for _, t := range customTypes {
+		if t == name {
+			return
+		}
+	}
+	customTypes = append(customTypes, name)
+}
+
+func registerEnum(en *Enum) {
+	name := en.QualifiedName()
+	pgToPgxTypeMap[name] = en.ExportedName()
+	pgToGoTypeMap[name] = en.ExportedName()
+	pgToGoTemplate[name] = func(t string) func(v, p string) string {
+		return func(v, p string) string { return fmt.Sprintf("%s(%s.%s.String)", t, v, p) }
+	}(en.GoType())
+	pgStringTemplate[name] = func(t string) func(v ...interface{}) string {
+		return func(v ...interface{}) string { return fmt.Sprintf("%s."+t+"ToString(%s)", v...) }
+	}(en.ExportedName())
+	goToPgTemplate[name] = func(v string) string { return fmt.Sprintf("%s.PGType()", v) }
+	addCustomType(name)
+}
+
+// registerComposite maps a composite and arrays of it to the generated
+// struct and array types.
+func registerComposite(c *Composite) {
+	name, arr := c.QualifiedName(), c.Schema+"._"+c.Name
+	t := c.ExportedName()
+	pgToPgxTypeMap[name] = t
+	pgToPgxTypeMap[arr] = t + "Array"
+	pgToGoTypeMap[name] = t
+	pgToGoTypeMap[arr] = "[]" + t
+	pgToGoTemplate[name] = func(v, p string) string { return fmt.Sprintf("%s.%s", v, p) }
+	pgToGoTemplate[arr] = func(v, p string) string { return fmt.Sprintf("%s.%s.Elements", v, p) }
+	pgStringTemplate[name] = func(v ...interface{}) string { return fmt.Sprintf("%s."+t+"ToString(%s)", v...) }
+	goToPgTemplate[name] = func(v string) string { return v }
+	goToPgTemplate[arr] = func(v string) string { return fmt.Sprintf("New%sArray(%s)", t, v) }
+	addCustomType(name)
+	addCustomType(arr)
+}
+
+// setPrimaryKeys marks the columns of the primary index of t as its primary
+// keys, in column order.
+func setPrimaryKeys(t *Table) {
+	t.PrimaryKeys = []*Column{}
+	for _, c := range t.Columns {
+		for _, ix := range t.Indexes {
+			if ix.IsPrimary && ix.hasColumn(c) {
+				c.IsPK = true
+				t.PrimaryKeys = append(t.PrimaryKeys, c)
+			}
+		}
+	}
+}
+
+// MinServerVersion is the oldest server version Inspect reads the catalog
+// of, as in server_version_num: generated columns are only in the catalog
+// of PostgreSQL 12 and later.
+const MinServerVersion = 120000
+
+// checkServerVersion returns an error if the server is older than
+// MinServerVersion.
+func checkServerVersion(conn *pgx.Conn) error {
+	var num int
+	var version string
+	err := conn.QueryRow("SELECT current_setting('server_version_num')::int, current_setting('server_version')").Scan(&num, &version)
+	if err != nil {
+		return errors.WithMessage(err, "querying server version")
+	}
+	if num < MinServerVersion {
+		return errors.Errorf("PostgreSQL 12+ required, the server is version %s", version)
+	}
+	return nil
+}
+
+func Inspect(conn *pgx.Conn, schemas ...string) (*PGData, error) {
+	if err := checkServerVersion(conn); err != nil {
+		return nil, err
+	}
 	data := &PGData{}
-	enums, err := getEnums(conn)
+	enums, err := getEnums(conn, schemas)
 	if err != nil {
 		return nil, errors.WithMessage(err, "querying enums")
 	}
 	data.Enums = enums
-	for name, en := range enums {
-		pgToPgxTypeMap[name] = en.ExportedName()
-		pgToGoTypeMap[name] = en.ExportedName()
-		pgToGoTemplate[name] = func(t string) func(v, p string) string {
-			return func(v, p string) string { return fmt.Sprintf("%s(%s.%s.String)", t, v, p) }
-		}(en.GoType())
-		pgStringTemplate[name] = func(t string) func(v ...interface{}) string {
-			return func(v ...interface{}) string { return fmt.Sprintf("%s." + t + "ToString(%s)", v...) }
-		}( en.ExportedName())
-		goToPgTemplate[name] = func(v string) string { return fmt.Sprintf("%s.PGType()", v) }
-		customEnumType = append(customEnumType, name)
-	}
-
-	tables, err := getTables(conn, schema)
+	for _, en := range enums {
+		registerEnum(en)
+	}
+
+	domains, err := getDomains(conn)
+	if err != nil {
+		return nil, errors.WithMessage(err, "querying domains")
+	}
+	data.Domains = map[string]*Domain{}
+	for k, d := range domains {
+		for _, s := range schemas {
+			if d.Schema == s {
+				data.Domains[k] = d
+			}
+		}
+	}
+
+	composites, err := getComposites(conn, schemas)
+	if err != nil {
+		return nil, errors.WithMessage(err, "querying composite types")
+	}
+	data.Composites = composites
+	for _, c := range composites {
+		resolveDomains(c.Attributes, domains)
+		registerComposite(c)
+	}
+
+	tables, err := getTables(conn, schemas)
 	if err != nil {
 		return nil, errors.WithMessage(err, "querying tables")
 	}
 	data.Tables = tables
+	if err := getInheritance(conn, schemas, tables); err != nil {
+		return nil, errors.WithMessage(err, "querying inheritance")
+	}
+
+	// The columns, keys and constraints of all tables are read with one query
+	// each, rather than per table, so the number of round trips doesn't grow
+	// with the schema.
+	if err := getColumns(conn, schemas, tables); err != nil {
+		return nil, errors.WithMessage(err, "querying columns")
+	}
 	for _, t := range tables {
-		cols, err := getColumns(conn, schema, t.Name)
-		if err != nil {
-			return nil, errors.WithMessage(err, "")
-		}
-		t.Columns = cols
+		resolveDomains(t.Columns, domains)
+	}
 
-		pkColName, err := getTablePrimaryIndex(conn, schema, t.Name)
-		if err != nil {
-			return nil, errors.WithMessage(err, "")
-		}
-		t.PrimaryKeys = []*Column{}
-		for _, c := range t.Columns {
-			for _, pkCol := range pkColName {
-				if c.Name == pkCol {
-					c.IsPK = true
-					t.PrimaryKeys = append(t.PrimaryKeys, c)
-				}
-			}
-		}
+	if err := getIndexes(conn, schemas, tables); err != nil {
+		return nil, errors.WithMessage(err, "querying indexes")
+	}
+	for _, t := range tables {
+		setPrimaryKeys(t)
+	}
+
+	if err := getForeignKeys(conn, schemas, tables); err != nil {
+		return nil, errors.WithMessage(err, "querying foreign keys")
+	}
+	if err := getChecks(conn, schemas, tables); err != nil {
+		return nil, errors.WithMessage(err, "querying check constraints")
+	}
+
+	functions, err := getFunctions(conn, schemas)
+	if err != nil {
+		return nil, errors.WithMessage(err, "querying functions")
 	}
+	for _, f := range functions {
+		resolveDomains(f.Args, domains)
+		resolveDomains(f.Results, domains)
+	}
+	data.Functions = functions
+
+	sequences, err := getSequences(conn, schemas)
+	if err != nil {
+		return nil, errors.WithMessage(err, "querying sequences")
+	}
+	data.Sequences = sequences
 	return data, nil
 }
 
 const (
 	queryGetTables = `
 SELECT
-  table_catalog,
-  table_schema,
-  table_name
+  table_catalog::TEXT,
+  table_schema::TEXT,
+  table_name::TEXT,
+  CASE table_type
+    WHEN 'VIEW' THEN 'view'
+    WHEN 'FOREIGN' THEN 'foreign table'
+    ELSE 'table'
+  END,
+  COALESCE(obj_description(format('%I.%I', table_schema, table_name)::REGCLASS, 'pg_class'), '')
 FROM information_schema.tables
-WHERE table_schema = $1;`
+WHERE table_schema = ANY ($1)
+UNION ALL
+SELECT
+  current_database()::TEXT,
+  schemaname::TEXT,
+  matviewname::TEXT,
+  'materialized view',
+  COALESCE(obj_description(format('%I.%I', schemaname, matviewname)::REGCLASS, 'pg_class'), '')
+FROM pg_matviews
+WHERE schemaname = ANY ($1);`
 
-	queryGetTablePrimaryIndex = `
+	// Partitioned tables and their partitions are both listed as base tables
+	// by information_schema; which is which is read from pg_inherits and
+	// pg_partitioned_table.
+	queryGetInheritance = `
 SELECT
-  pg_attribute.attname
-FROM pg_index, pg_class, pg_attribute, pg_namespace
+  n.nspname::TEXT,
+  c.relname::TEXT,
+  ARRAY(
+      SELECT pn.nspname || '.' || p.relname
+      FROM pg_inherits i
+        JOIN pg_class p ON p.oid = i.inhparent
+        JOIN pg_namespace pn ON pn.oid = p.relnamespace
+      WHERE i.inhrelid = c.oid
+      ORDER BY i.inhseqno
+  )::TEXT[],
+  CASE WHEN pt.partrelid IS NOT NULL THEN pg_get_partkeydef(c.oid) ELSE '' END,
+  COALESCE(pg_get_expr(c.relpartbound, c.oid), '')
+FROM pg_class c
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  LEFT JOIN pg_partitioned_table pt ON pt.partrelid = c.oid
+WHERE n.nspname = ANY ($1)
+  AND c.relkind IN ('r', 'p', 'f')
+  AND (pt.partrelid IS NOT NULL OR EXISTS (SELECT 1 FROM pg_inherits i WHERE i.inhrelid = c.oid));`
+
+	// Functions belonging to extensions are left out, as are aggregates and
+	// window functions. Argument types are read from proallargtypes, which
+	// includes OUT arguments, when there are any.
+	queryGetFunctions = `
+SELECT
+  n.nspname::TEXT,
+  p.proname::TEXT,
+  CASE p.prokind WHEN 'p' THEN 'procedure' ELSE 'function' END,
+  p.proretset,
+  COALESCE(rt.typname::TEXT, ''),
+  COALESCE(rtn.nspname::TEXT, ''),
+  COALESCE(p.proargnames, '{}')::TEXT[],
+  COALESCE(p.proargmodes::TEXT[], '{}'),
+  ARRAY(
+      SELECT t.typname
+      FROM unnest(COALESCE(p.proallargtypes, p.proargtypes::OID[])) WITH ORDINALITY AS a(oid, ord)
+        JOIN pg_type t ON t.oid = a.oid
+      ORDER BY a.ord
+  )::TEXT[],
+  ARRAY(
+      SELECT tn.nspname
+      FROM unnest(COALESCE(p.proallargtypes, p.proargtypes::OID[])) WITH ORDINALITY AS a(oid, ord)
+        JOIN pg_type t ON t.oid = a.oid
+        JOIN pg_namespace tn ON tn.oid = t.typnamespace
+      ORDER BY a.ord
+  )::TEXT[],
+  COALESCE(obj_description(p.oid, 'pg_proc'), '')
+FROM pg_proc p
+  JOIN pg_namespace n ON n.oid = p.pronamespace
+  LEFT JOIN pg_type rt ON rt.oid = p.prorettype
+  LEFT JOIN pg_namespace rtn ON rtn.oid = rt.typnamespace
+WHERE n.nspname = ANY ($1)
+  AND p.prokind IN ('f', 'p')
+  AND NOT EXISTS (
+      SELECT 1
+      FROM pg_depend d
+      WHERE d.classid = 'pg_proc'::REGCLASS AND d.objid = p.oid AND d.deptype = 'e'
+  )
+ORDER BY n.nspname, p.proname, p.oid;`
+
+	// The column owning a sequence is found from its automatic dependency
+	// for serial columns, or its internal one for identity columns.
+	queryGetSequences = `
+SELECT
+  n.nspname::TEXT,
+  c.relname::TEXT,
+  t.typname::TEXT,
+  COALESCE(tn.nspname || '.' || tc.relname, ''),
+  COALESCE(a.attname::TEXT, ''),
+  COALESCE(obj_description(c.oid, 'pg_class'), '')
+FROM pg_sequence s
+  JOIN pg_class c ON c.oid = s.seqrelid
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_type t ON t.oid = s.seqtypid
+  LEFT JOIN pg_depend d ON d.classid = 'pg_class'::REGCLASS AND d.objid = c.oid
+    AND d.refclassid = 'pg_class'::REGCLASS AND d.refobjsubid > 0 AND d.deptype IN ('a', 'i')
+  LEFT JOIN pg_class tc ON tc.oid = d.refobjid
+  LEFT JOIN pg_namespace tn ON tn.oid = tc.relnamespace
+  LEFT JOIN pg_attribute a ON a.attrelid = d.refobjid AND a.attnum = d.refobjsubid
+WHERE n.nspname = ANY ($1)
+  AND NOT EXISTS (
+      SELECT 1
+      FROM pg_depend e
+      WHERE e.classid = 'pg_class'::REGCLASS AND e.objid = c.oid AND e.deptype = 'e'
+  )
+ORDER BY n.nspname, c.relname;`
+
+	queryGetChecks = `
+SELECT
+  n.nspname::TEXT,
+  c.relname::TEXT,
+  con.conname::TEXT,
+  pg_get_constraintdef(con.oid, true)
+FROM pg_constraint con
+  JOIN pg_class c ON c.oid = con.conrelid
+  JOIN pg_namespace n ON n.oid = c.relnamespace
 WHERE
-  pg_class.oid = $2::regclass AND
-  indrelid = pg_class.oid AND
-  nspname = $1 AND
-  pg_class.relnamespace = pg_namespace.oid AND
-  pg_attribute.attrelid = pg_class.oid AND
-  pg_attribute.attnum = any(pg_index.indkey)
- AND indisprimary
+  n.nspname = ANY ($1) AND
+  con.contype = 'c'
+ORDER BY n.nspname, c.relname, con.conname;`
+
+	queryGetForeignKeys = `
+SELECT
+  n.nspname::TEXT,
+  c.relname::TEXT,
+  con.conname,
+  rn.nspname,
+  rc.relname,
+  ARRAY(
+      SELECT a.attname
+      FROM unnest(con.conkey) WITH ORDINALITY AS k(attnum, ord)
+        JOIN pg_attribute a ON a.attrelid = con.conrelid AND a.attnum = k.attnum
+      ORDER BY k.ord
+  )::TEXT[] AS columns,
+  ARRAY(
+      SELECT a.attname
+      FROM unnest(con.confkey) WITH ORDINALITY AS k(attnum, ord)
+        JOIN pg_attribute a ON a.attrelid = con.confrelid AND a.attnum = k.attnum
+      ORDER BY k.ord
+  )::TEXT[] AS ref_columns,
+  CASE con.confdeltype
+    WHEN 'r' THEN 'RESTRICT'
+    WHEN 'c' THEN 'CASCADE'
+    WHEN 'n' THEN 'SET NULL'
+    WHEN 'd' THEN 'SET DEFAULT'
+    ELSE 'NO ACTION'
+  END AS on_delete,
+  CASE con.confupdtype
+    WHEN 'r' THEN 'RESTRICT'
+    WHEN 'c' THEN 'CASCADE'
+    WHEN 'n' THEN 'SET NULL'
+    WHEN 'd' THEN 'SET DEFAULT'
+    ELSE 'NO ACTION'
+  END AS on_update
+FROM pg_constraint con
+  JOIN pg_class c ON c.oid = con.conrelid
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_class rc ON rc.oid = con.confrelid
+  JOIN pg_namespace rn ON rn.oid = rc.relnamespace
+WHERE con.contype = 'f'
+  AND n.nspname = ANY ($1)
+ORDER BY n.nspname, c.relname, con.conname;
 `
 
+	// Columns are read from pg_attribute rather than information_schema.columns,
+	// which doesn't list the columns of materialized views.
 	queryGetColumns = `
-SELECT ordinal_position, column_name, udt_name, is_nullable
-FROM information_schema.columns
-WHERE table_schema = $1
-  AND table_name   = $2;
+SELECT
+  n.nspname::TEXT,
+  c.relname::TEXT,
+  a.attnum::INT4,
+  a.attname::TEXT,
+  t.typname::TEXT,
+  tn.nspname::TEXT,
+  CASE WHEN a.attnotnull OR (t.typtype = 'd' AND t.typnotnull) THEN 'NO' ELSE 'YES' END,
+  CASE WHEN a.attgenerated = '' THEN COALESCE(pg_get_expr(d.adbin, d.adrelid, TRUE), '') ELSE '' END,
+  CASE a.attidentity WHEN 'a' THEN 'ALWAYS' WHEN 'd' THEN 'BY DEFAULT' ELSE '' END,
+  CASE WHEN a.attgenerated <> '' THEN COALESCE(pg_get_expr(d.adbin, d.adrelid, TRUE), '') ELSE '' END,
+  COALESCE(col_description(a.attrelid, a.attnum), ''),
+  CASE WHEN information_schema._pg_truetypid(a.*, t.*) = 'numeric'::REGTYPE
+    THEN COALESCE(information_schema._pg_numeric_precision(information_schema._pg_truetypid(a.*, t.*), information_schema._pg_truetypmod(a.*, t.*)), 0)
+    ELSE 0
+  END::INT4,
+  CASE WHEN information_schema._pg_truetypid(a.*, t.*) = 'numeric'::REGTYPE
+    THEN COALESCE(information_schema._pg_numeric_scale(information_schema._pg_truetypid(a.*, t.*), information_schema._pg_truetypmod(a.*, t.*)), 0)
+    ELSE 0
+  END::INT4
+FROM pg_attribute a
+  JOIN pg_class c ON c.oid = a.attrelid
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_type t ON t.oid = a.atttypid
+  JOIN pg_namespace tn ON tn.oid = t.typnamespace
+  LEFT JOIN pg_attrdef d ON d.adrelid = a.attrelid AND d.adnum = a.attnum
+WHERE n.nspname = ANY ($1)
+  AND c.relkind IN ('r', 'v', 'm', 'f', 'p')
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+ORDER BY n.nspname, c.relname, a.attnum;
 `
 
 	queryGetEnums = `
@@ -362,30 +1358,104 @@ SELECT
   e.enumlabel AS enum_value
 FROM pg_type t
   JOIN pg_enum e ON t.oid = e.enumtypid
-  JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace;
+  JOIN pg_catalog.pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname = ANY ($1)
+ORDER BY n.nspname, t.typname, e.enumsortorder;
+`
+	queryGetDomains = `
+WITH RECURSIVE base AS (
+  SELECT t.oid AS domain_oid, t.typbasetype AS base_oid
+  FROM pg_type t
+  WHERE t.typtype = 'd'
+  UNION ALL
+  SELECT base.domain_oid, b.typbasetype
+  FROM base
+    JOIN pg_type b ON b.oid = base.base_oid
+  WHERE b.typtype = 'd'
+)
+SELECT
+  n.nspname::TEXT,
+  t.typname::TEXT,
+  bt.typname::TEXT,
+  bn.nspname::TEXT,
+  t.typnotnull,
+  COALESCE(t.typdefault, ''),
+  ARRAY(
+      SELECT pg_get_constraintdef(c.oid, TRUE)
+      FROM pg_constraint c
+      WHERE c.contypid = t.oid AND c.contype = 'c'
+      ORDER BY c.conname
+  )::TEXT[]
+FROM base
+  JOIN pg_type t ON t.oid = base.domain_oid
+  JOIN pg_namespace n ON n.oid = t.typnamespace
+  JOIN pg_type bt ON bt.oid = base.base_oid AND bt.typtype <> 'd'
+  JOIN pg_namespace bn ON bn.oid = bt.typnamespace
+WHERE n.nspname NOT IN ('pg_catalog', 'information_schema');
 `
-	queryGetTableIndexes = `
+
+	queryGetComposites = `
 SELECT
+  n.nspname::TEXT,
+  t.typname::TEXT,
+  a.attnum::INT4,
+  a.attname::TEXT,
+  at.typname::TEXT,
+  atn.nspname::TEXT,
+  COALESCE(col_description(c.oid, a.attnum), '')
+FROM pg_type t
+  JOIN pg_namespace n ON n.oid = t.typnamespace
+  JOIN pg_class c ON c.oid = t.typrelid
+  JOIN pg_attribute a ON a.attrelid = c.oid
+  JOIN pg_type at ON at.oid = a.atttypid
+  JOIN pg_namespace atn ON atn.oid = at.typnamespace
+WHERE t.typtype = 'c'
+  AND c.relkind = 'c'
+  AND a.attnum > 0
+  AND NOT a.attisdropped
+  AND n.nspname = ANY ($1)
+ORDER BY n.nspname, t.typname, a.attnum;
+`
+
+	queryGetIndexes = `
+SELECT
+  ns.nspname::TEXT,
+  t.relname::TEXT,
   i.relname AS index_name,
-  idx.indrelid :: REGCLASS as table_name,
+  am.amname AS index_method,
+  idx.indisunique,
+  idx.indisprimary,
+  COALESCE(pg_get_expr(idx.indpred, idx.indrelid, TRUE), '') AS index_predicate,
   ARRAY(
       SELECT pg_get_indexdef(idx.indexrelid, k + 1, TRUE)
       FROM generate_subscripts(idx.indkey, 1) AS k
+      WHERE k < idx.indnkeyatts
       ORDER BY k
-  )         AS index_columns
+  )::TEXT[]  AS index_keys,
+  ARRAY(
+      SELECT COALESCE(a.attname, '')
+      FROM generate_subscripts(idx.indkey, 1) AS k
+        LEFT JOIN pg_attribute AS a
+          ON a.attrelid = idx.indrelid AND a.attnum = idx.indkey[k]
+      WHERE k < idx.indnkeyatts
+      ORDER BY k
+  )::TEXT[]  AS index_columns
 FROM pg_index AS idx
   JOIN pg_class AS i
     ON i.oid = idx.indexrelid
+  JOIN pg_class AS t
+    ON t.oid = idx.indrelid
   JOIN pg_am AS am
     ON i.relam = am.oid
   JOIN pg_namespace AS ns
-    ON ns.oid = i.relnamespace
-       AND ns.nspname = ANY (current_schemas(FALSE));
+    ON ns.oid = t.relnamespace
+WHERE ns.nspname = ANY ($1)
+ORDER BY ns.nspname, t.relname, i.relname;
 `
 )
 
-func getEnums(conn *pgx.Conn) (map[string]*Enum, error) {
-	rows, err := conn.Query(queryGetEnums)
+func getEnums(conn *pgx.Conn, schemas []string) (map[string]*Enum, error) {
+	rows, err := conn.Query(queryGetEnums, schemas)
 	defer rows.Close()
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -399,9 +1469,10 @@ func getEnums(conn *pgx.Conn) (map[string]*Enum, error) {
 			return nil, errors.WithStack(err)
 		}
 	getEnum:
-		en, ok := enMap[name]
+		en, ok := enMap[sch+"."+name]
 		if !ok {
-			enMap[name] = &Enum{
+			enMap[sch+"."+name] = &Enum{
+				Schema: sch,
 				Name:   name,
 				Values: []*EnumValue{},
 			}
@@ -412,8 +1483,72 @@ func getEnums(conn *pgx.Conn) (map[string]*Enum, error) {
 	return enMap, nil
 }
 
-func getTables(conn *pgx.Conn, schema string) (map[string]*Table, error) {
-	rows, err := conn.Query(queryGetTables, schema)
+func getDomains(conn *pgx.Conn) (map[string]*Domain, error) {
+	rows, err := conn.Query(queryGetDomains)
+	defer rows.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	domains := map[string]*Domain{}
+	for rows.Next() {
+		var d Domain
+		var checks pgtype.TextArray
+		err := rows.Scan(&d.Schema, &d.Name, &d.BaseType, &d.BaseTypeSchema, &d.NotNull, &d.Default, &checks)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		if err := checks.AssignTo(&d.Checks); err != nil {
+			return nil, errors.WithStack(err)
+		}
+		domains[d.QualifiedName()] = &d
+	}
+	return domains, nil
+}
+
+// resolveDomains replaces the type of columns typed with a domain, or an
+// array of one, by the domain's base type. The domain of non-array columns is
+// kept in Column.Domain.
+func resolveDomains(cols []*Column, domains map[string]*Domain) {
+	for _, c := range cols {
+		if d, ok := domains[c.typeKey()]; ok {
+			c.Domain = d.QualifiedName()
+			c.DataType, c.TypeSchema = d.BaseType, d.BaseTypeSchema
+			continue
+		}
+		if d, ok := domains[c.elemTypeKey()]; ok {
+			c.DataType, c.TypeSchema = "_"+d.BaseType, d.BaseTypeSchema
+		}
+	}
+}
+
+func getComposites(conn *pgx.Conn, schemas []string) (map[string]*Composite, error) {
+	rows, err := conn.Query(queryGetComposites, schemas)
+	defer rows.Close()
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	cMap := map[string]*Composite{}
+	for rows.Next() {
+		var sch, name string
+		attr := Column{Nullable: true}
+		err := rows.Scan(&sch, &name, &attr.Position, &attr.Name, &attr.DataType, &attr.TypeSchema, &attr.Comment)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		c, ok := cMap[sch+"."+name]
+		if !ok {
+			c = &Composite{Schema: sch, Name: name}
+			cMap[sch+"."+name] = c
+		}
+		c.Attributes = append(c.Attributes, &attr)
+	}
+	return cMap, nil
+}
+
+func getTables(conn *pgx.Conn, schemas []string) (map[string]*Table, error) {
+	rows, err := conn.Query(queryGetTables, schemas)
 	defer rows.Close()
 	if err != nil {
 		return nil, errors.Errorf("unable to get tables: %v", err)
@@ -422,27 +1557,154 @@ func getTables(conn *pgx.Conn, schema string) (map[string]*Table, error) {
 	tables := make(map[string]*Table)
 	for rows.Next() {
 		var table Table
-		err := rows.Scan(&table.Catalog, &table.Schema, &table.Name)
+		err := rows.Scan(&table.Catalog, &table.Schema, &table.Name, &table.Kind, &table.Comment)
 		if err != nil {
 			return nil, err
 		}
-		tables[table.Name] = &table
+		tables[table.QualifiedName()] = &table
 	}
 	return tables, nil
 }
 
-func getColumns(conn *pgx.Conn, schema, table string) ([]*Column, error) {
-	rows, err := conn.Query(queryGetColumns, schema, table)
+// getInheritance reads the parents, partition keys and partition bounds of
+// the tables in schemas.
+func getInheritance(conn *pgx.Conn, schemas []string, tables map[string]*Table) error {
+	rows, err := conn.Query(queryGetInheritance, schemas)
+	defer rows.Close()
+	if err != nil {
+		return fmt.Errorf("unable to get inheritance: %v", err)
+	}
+
+	for rows.Next() {
+		var sch, name string
+		var parents pgtype.TextArray
+		var key, bound string
+		if err := rows.Scan(&sch, &name, &parents, &key, &bound); err != nil {
+			return err
+		}
+		t, ok := tables[sch+"."+name]
+		if !ok {
+			continue
+		}
+		if err := parents.AssignTo(&t.Inherits); err != nil {
+			return err
+		}
+		t.PartitionKey, t.PartitionBound = key, bound
+	}
+	return rows.Err()
+}
+
+// getFunctions reads the functions and procedures in schemas. Variadic
+// functions, and procedures with OUT arguments, are skipped.
+func getFunctions(conn *pgx.Conn, schemas []string) (map[string]*Function, error) {
+	rows, err := conn.Query(queryGetFunctions, schemas)
+	defer rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get functions: %v", err)
+	}
+
+	functions := map[string]*Function{}
+	for rows.Next() {
+		var f Function
+		var retType, retSchema string
+		var names, modes, types, typeSchemas pgtype.TextArray
+		err := rows.Scan(&f.Schema, &f.Name, &f.Kind, &f.ReturnsSet, &retType, &retSchema, &names, &modes, &types, &typeSchemas, &f.Comment)
+		if err != nil {
+			return nil, err
+		}
+		var nn, mm, tt, ss []string
+		for _, a := range []struct {
+			src *pgtype.TextArray
+			dst *[]string
+		}{{&names, &nn}, {&modes, &mm}, {&types, &tt}, {&typeSchemas, &ss}} {
+			if err := a.src.AssignTo(a.dst); err != nil {
+				return nil, err
+			}
+		}
+		if !f.addArgs(nn, mm, tt, ss) {
+			continue
+		}
+		if len(f.Results) == 0 && retType != "void" && !f.IsProcedure() {
+			f.Results = []*Column{{Position: 1, Nullable: true, DataType: retType, TypeSchema: retSchema}}
+		}
+		functions[f.Signature()] = &f
+	}
+	return functions, rows.Err()
+}
+
+// getSequences reads the sequences in schemas.
+func getSequences(conn *pgx.Conn, schemas []string) (map[string]*Sequence, error) {
+	rows, err := conn.Query(queryGetSequences, schemas)
+	defer rows.Close()
+	if err != nil {
+		return nil, fmt.Errorf("unable to get sequences: %v", err)
+	}
+
+	sequences := map[string]*Sequence{}
+	for rows.Next() {
+		var sq Sequence
+		err := rows.Scan(&sq.Schema, &sq.Name, &sq.DataType, &sq.OwnerTable, &sq.OwnerColumn, &sq.Comment)
+		if err != nil {
+			return nil, err
+		}
+		sequences[sq.QualifiedName()] = &sq
+	}
+	return sequences, rows.Err()
+}
+
+// addArgs sorts the arguments of f into input arguments and results by
+// their modes, and reports whether f can be called with them.
+func (f *Function) addArgs(names, modes, types, typeSchemas []string) bool {
+	for k := range types {
+		mode := "i"
+		if k < len(modes) {
+			mode = modes[k]
+		}
+		c := &Column{Nullable: true, DataType: types[k], TypeSchema: typeSchemas[k]}
+		if k < len(names) {
+			c.Name = names[k]
+		}
+		switch mode {
+		case "i", "b":
+			if c.Name == "" {
+				c.Name = fmt.Sprintf("arg%d", len(f.Args)+1)
+			}
+			c.Position = len(f.Args) + 1
+			f.Args = append(f.Args, c)
+			if mode == "i" {
+				continue
+			}
+			c = &Column{Nullable: true, Name: c.Name, DataType: c.DataType, TypeSchema: c.TypeSchema}
+		case "o", "t":
+			if f.IsProcedure() {
+				return false
+			}
+			if c.Name == "" {
+				c.Name = fmt.Sprintf("column%d", len(f.Results)+1)
+			}
+		default:
+			// Variadic.
+			return false
+		}
+		c.Position = len(f.Results) + 1
+		f.Results = append(f.Results, c)
+	}
+	return true
+}
+
+// getColumns reads the columns of all tables in schemas.
+func getColumns(conn *pgx.Conn, schemas []string, tables map[string]*Table) error {
+	rows, err := conn.Query(queryGetColumns, schemas)
 	defer rows.Close()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get columns: %v", err)
+		return fmt.Errorf("unable to get columns: %v", err)
 	}
 
-	var cols []*Column
 	for rows.Next() {
+		var sch, name string
 		var col Column
 		var null string
-		err := rows.Scan(&col.Position, &col.Name, &col.DataType, &null)
+		err := rows.Scan(&sch, &name, &col.Position, &col.Name, &col.DataType, &col.TypeSchema, &null, &col.Default, &col.Identity, &col.Generated, &col.Comment, &col.Precision, &col.Scale)
 		if null == "YES" {
 			col.Nullable = true
 		}
@@ -450,59 +1712,108 @@ func getColumns(conn *pgx.Conn, schema, table string) ([]*Column, error) {
 			col.Nullable = false
 		}
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if t, ok := tables[sch+"."+name]; ok {
+			t.Columns = append(t.Columns, &col)
 		}
-		cols = append(cols, &col)
 	}
-	return cols, nil
+	return rows.Err()
 }
 
-//
-func getTablePrimaryIndex(conn *pgx.Conn, schema string, table string) ([]string, error) {
-	rows, err := conn.Query(queryGetTablePrimaryIndex, schema, table)
+// getForeignKeys reads the foreign keys of all tables in schemas. Their
+// columns must have been read.
+func getForeignKeys(conn *pgx.Conn, schemas []string, tables map[string]*Table) error {
+	rows, err := conn.Query(queryGetForeignKeys, schemas)
 	defer rows.Close()
 	if err != nil {
-		return nil, fmt.Errorf("unable to get tables: %v", err)
+		return fmt.Errorf("unable to get foreign keys: %v", err)
 	}
 
-	var idx []string
 	for rows.Next() {
-		var colName string
-		err := rows.Scan(&colName)
+		var sch, name string
+		var fk ForeignKey
+		var cols, refCols pgtype.TextArray
+		err := rows.Scan(&sch, &name, &fk.Name, &fk.RefSchema, &fk.RefTable, &cols, &refCols, &fk.OnDelete, &fk.OnUpdate)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		table, ok := tables[sch+"."+name]
+		if !ok {
+			continue
+		}
+		var colNames []string
+		if err := cols.AssignTo(&colNames); err != nil {
+			return err
+		}
+		if err := refCols.AssignTo(&fk.RefColumns); err != nil {
+			return err
 		}
-		idx = append(idx, colName)
+		for _, n := range colNames {
+			c := table.Column(n)
+			if c == nil {
+				return errors.Errorf("foreign key %s: unknown column %s.%s", fk.Name, table.Name, n)
+			}
+			fk.Columns = append(fk.Columns, c)
+		}
+		table.ForeignKeys = append(table.ForeignKeys, &fk)
 	}
-	return idx, nil
+	return rows.Err()
 }
 
-//
-// func getTableIndexes(conn *pgx.Conn) ([]*Index, error) {
-// 	rows, err := conn.QueryDefinition(queryGetTableIndexes)
-// 	defer rows.Close()
-// 	if err != nil {
-// 		return nil, fmt.Errorf("unable to get tables: %v", err)
-// 	}
-//
-// 	var idx []*Index
-// 	for rows.Next() {
-// 		var indexName string
-// 		var tableName string
-// 		var _indexCols pgtype.TextArray
-// 		var indexCols []string
-// 		err := rows.Scan(&indexName, &tableName, &_indexCols)
-// 		if err != nil {
-// 			return nil, err
-// 		}
-// 		_indexCols.AssignTo(&indexCols)
-// 		idx = append(idx, &Index{
-// 			Name:      indexName,
-// 			GoName:    replaceAbbr(stringcase.ToPascalCase(indexName)),
-// 			ShortName: shortName(replaceAbbr(stringcase.ToPascalCase(indexName))),
-// 			Table:     tableName,
-// 			Columns:   indexCols,
-// 		})
-// 	}
-// 	return idx, nil
-// }
+// getChecks reads the CHECK constraints of all tables in schemas.
+func getChecks(conn *pgx.Conn, schemas []string, tables map[string]*Table) error {
+	rows, err := conn.Query(queryGetChecks, schemas)
+	defer rows.Close()
+	if err != nil {
+		return fmt.Errorf("unable to get check constraints: %v", err)
+	}
+
+	for rows.Next() {
+		var sch, name string
+		var c Check
+		if err := rows.Scan(&sch, &name, &c.Name, &c.Definition); err != nil {
+			return err
+		}
+		if t, ok := tables[sch+"."+name]; ok {
+			t.Checks = append(t.Checks, &c)
+		}
+	}
+	return rows.Err()
+}
+
+// getIndexes reads the indexes of all tables in schemas. Their columns must
+// have been read.
+func getIndexes(conn *pgx.Conn, schemas []string, tables map[string]*Table) error {
+	rows, err := conn.Query(queryGetIndexes, schemas)
+	defer rows.Close()
+	if err != nil {
+		return fmt.Errorf("unable to get indexes: %v", err)
+	}
+
+	for rows.Next() {
+		var sch, name string
+		var ix Index
+		var keys, cols pgtype.TextArray
+		err := rows.Scan(&sch, &name, &ix.Name, &ix.Method, &ix.IsUnique, &ix.IsPrimary, &ix.Predicate, &keys, &cols)
+		if err != nil {
+			return err
+		}
+		table, ok := tables[sch+"."+name]
+		if !ok {
+			continue
+		}
+		if err := keys.AssignTo(&ix.Keys); err != nil {
+			return err
+		}
+		var colNames []string
+		if err := cols.AssignTo(&colNames); err != nil {
+			return err
+		}
+		for _, n := range colNames {
+			ix.Columns = append(ix.Columns, table.Column(n))
+		}
+		table.Indexes = append(table.Indexes, &ix)
+	}
+	return rows.Err()
+}
@@ -0,0 +1,67 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import "testing"
+
+func TestSchemaPrefixesOtherSchemas(t *testing.T) {
+	d, err := ParseDDL(`
+CREATE SCHEMA auth;
+CREATE TYPE public.mood AS ENUM ('happy', 'sad');
+CREATE TYPE auth.mood AS ENUM ('locked', 'open');
+CREATE TYPE public.address AS (street text, m public.mood);
+CREATE TABLE public.customers (id int, m public.mood);
+CREATE TABLE auth.users (id int, m auth.mood, customer_mood public.mood, addr public.address);
+`, "public", "auth")
+	if err != nil {
+		t.Fatalf("ParseDDL: %v", err)
+	}
+
+	auth := d.Schema("auth")
+	RegisterTypes(auth)
+	for k, want := range map[string]string{"auth.mood": "Mood", "public.mood": "PublicMood"} {
+		if en := auth.Enums[k]; en == nil || en.ExportedName() != want {
+			t.Errorf("enum %s in auth = %+v, want it named %s", k, en, want)
+		}
+	}
+	if ct := auth.Composites["public.address"]; ct == nil || ct.ExportedName() != "PublicAddress" {
+		t.Errorf("composite public.address in auth = %+v, want it named PublicAddress", ct)
+	}
+	users := auth.Tables["auth.users"]
+	for col, want := range map[string]string{"m": "Mood", "customer_mood": "PublicMood", "addr": "PublicAddress"} {
+		if got := users.Column(col).PgxType(); got != want {
+			t.Errorf("auth.users.%s type = %s, want %s", col, got, want)
+		}
+	}
+
+	public := d.Schema("public")
+	RegisterTypes(public)
+	if _, ok := public.Enums["auth.mood"]; ok {
+		t.Error("enum auth.mood is in public, which doesn't use it")
+	}
+	if en := public.Enums["public.mood"]; en == nil || en.ExportedName() != "Mood" {
+		t.Errorf("enum public.mood in public = %+v, want it named Mood", en)
+	}
+	if got := public.Tables["public.customers"].Column("m").PgxType(); got != "Mood" {
+		t.Errorf("public.customers.m type = %s, want Mood", got)
+	}
+	d.Schema("auth")
+	if got := public.Tables["public.customers"].Column("m").PgxType(); got != "Mood" {
+		t.Errorf("public.customers.m type = %s after taking the auth subset, want Mood", got)
+	}
+	if en := d.Enums["public.mood"]; en.Prefix != "" {
+		t.Errorf("enum public.mood of the whole data got prefix %q", en.Prefix)
+	}
+}
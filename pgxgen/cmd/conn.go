@@ -0,0 +1,284 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+)
+
+var dbDSN string
+var dbHost string
+var dbPort uint16
+var dbUser string
+var dbPassword string
+var dbName string
+var dbSSLMode string
+var dbSSLRootCert string
+var dbSSLCert string
+var dbSSLKey string
+
+// connect opens a connection to the database to inspect.
+func connect() (*pgx.Conn, error) {
+	cc, err := connConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pgx.Connect(cc)
+	if err != nil {
+		return nil, errors.WithMessage(err, describeConn(cc))
+	}
+	return conn, nil
+}
+
+// connConfig builds the connection configuration the way libpq does. The PG*
+// environment variables are read first, then the connection string given by
+// --dsn or DATABASE_URL, then the individual --db* and --ssl* flags; later
+// sources take precedence. As in libpq, the user defaults to the current
+// user, and when no password is given it is looked up in PGPASSFILE or
+// ~/.pgpass.
+func connConfig() (pgx.ConnConfig, error) {
+	cc, err := pgx.ParseEnvLibpq()
+	if err != nil {
+		return cc, errors.WithMessage(err, "PG environment variables")
+	}
+	password := os.Getenv("PGPASSWORD")
+	ssl := map[string]string{
+		"sslmode":     os.Getenv("PGSSLMODE"),
+		"sslrootcert": os.Getenv("PGSSLROOTCERT"),
+		"sslcert":     os.Getenv("PGSSLCERT"),
+		"sslkey":      os.Getenv("PGSSLKEY"),
+	}
+
+	dsn := dbDSN
+	if dsn == "" {
+		dsn = os.Getenv("DATABASE_URL")
+	}
+	if dsn != "" {
+		dc, err := pgx.ParseConnectionString(dsn)
+		if err != nil {
+			return cc, errors.WithMessage(err, "connection string")
+		}
+		params, err := connStringParams(dsn)
+		if err != nil {
+			return cc, errors.WithMessage(err, "connection string")
+		}
+		if params["password"] != "" {
+			password = params["password"]
+		}
+		for k := range ssl {
+			if params[k] != "" {
+				ssl[k] = params[k]
+			}
+			delete(dc.RuntimeParams, k)
+		}
+		// TLS is configured below, once the host is known.
+		dc.TLSConfig, dc.UseFallbackTLS, dc.FallbackTLSConfig = nil, false, nil
+		cc = cc.Merge(dc)
+	}
+
+	cc = cc.Merge(pgx.ConnConfig{Host: dbHost, Port: dbPort, User: dbUser, Database: dbName})
+	if dbPassword != "" {
+		password = dbPassword
+	}
+	for k, v := range map[string]string{"sslmode": dbSSLMode, "sslrootcert": dbSSLRootCert, "sslcert": dbSSLCert, "sslkey": dbSSLKey} {
+		if v != "" {
+			ssl[k] = v
+		}
+	}
+
+	if cc.Host == "" {
+		cc.Host = "localhost"
+	}
+	if cc.User == "" {
+		u, err := user.Current()
+		if err != nil {
+			return cc, errors.WithMessage(err, "default user")
+		}
+		cc.User = u.Username
+	}
+	if password == "" {
+		password = pgpass(cc)
+	}
+	cc.Password = password
+
+	if err := configTLS(&cc, ssl); err != nil {
+		return cc, err
+	}
+	return cc, nil
+}
+
+var dsnParamRegexp = regexp.MustCompile(`([a-zA-Z_]+)=((?:"[^"]+")|(?:[^ ]+))`)
+
+// connStringParams returns the password and ssl* parameters of a URI or
+// key/value connection string, which pgx either doesn't report as given or
+// doesn't support.
+func connStringParams(s string) (map[string]string, error) {
+	params := map[string]string{}
+	if strings.HasPrefix(s, "postgres://") || strings.HasPrefix(s, "postgresql://") {
+		u, err := url.Parse(s)
+		if err != nil {
+			return nil, err
+		}
+		if u.User != nil {
+			params["password"], _ = u.User.Password()
+		}
+		for k, v := range u.Query() {
+			params[k] = v[0]
+		}
+		return params, nil
+	}
+	for _, m := range dsnParamRegexp.FindAllStringSubmatch(s, -1) {
+		params[m[1]] = strings.Trim(m[2], `"`)
+	}
+	return params, nil
+}
+
+// configTLS sets up TLS following libpq's sslmode, sslrootcert, sslcert and
+// sslkey parameters. As in pgx, verify-ca is treated as verify-full.
+func configTLS(cc *pgx.ConnConfig, ssl map[string]string) error {
+	cc.TLSConfig, cc.UseFallbackTLS, cc.FallbackTLSConfig = nil, false, nil
+
+	var tc *tls.Config
+	switch mode := ssl["sslmode"]; mode {
+	case "disable":
+		return nil
+	case "allow":
+		cc.UseFallbackTLS = true
+		cc.FallbackTLSConfig = &tls.Config{InsecureSkipVerify: true}
+		tc = cc.FallbackTLSConfig
+	case "", "prefer":
+		cc.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+		cc.UseFallbackTLS = true
+		tc = cc.TLSConfig
+	case "require":
+		cc.TLSConfig = &tls.Config{InsecureSkipVerify: true}
+		tc = cc.TLSConfig
+	case "verify-ca", "verify-full":
+		cc.TLSConfig = &tls.Config{ServerName: cc.Host}
+		tc = cc.TLSConfig
+	default:
+		return errors.Errorf("invalid sslmode %q", mode)
+	}
+
+	if f := ssl["sslrootcert"]; f != "" {
+		pem, err := ioutil.ReadFile(f)
+		if err != nil {
+			return errors.WithMessage(err, "reading sslrootcert")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return errors.Errorf("sslrootcert %s: no certificates found", f)
+		}
+		tc.RootCAs = pool
+	}
+	if ssl["sslcert"] != "" || ssl["sslkey"] != "" {
+		cert, err := tls.LoadX509KeyPair(ssl["sslcert"], ssl["sslkey"])
+		if err != nil {
+			return errors.WithMessage(err, "loading sslcert and sslkey")
+		}
+		tc.Certificates = []tls.Certificate{cert}
+	}
+	return nil
+}
+
+// pgpass looks up the password for cc in PGPASSFILE, or ~/.pgpass. See
+// https://www.postgresql.org/docs/current/static/libpq-pgpass.html
+func pgpass(cc pgx.ConnConfig) string {
+	passfile := os.Getenv("PGPASSFILE")
+	if passfile == "" {
+		u, err := user.Current()
+		if err != nil {
+			return ""
+		}
+		passfile = filepath.Join(u.HomeDir, ".pgpass")
+	}
+	f, err := os.Open(passfile)
+	if err != nil {
+		return ""
+	}
+	defer f.Close()
+
+	port := "5432"
+	if cc.Port != 0 {
+		port = strconv.Itoa(int(cc.Port))
+	}
+	want := []string{cc.Host, port, cc.Database, cc.User}
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		parts := splitPgpass(line)
+		if len(parts) != 5 {
+			continue
+		}
+		match := true
+		for i, w := range want {
+			if parts[i] != "*" && parts[i] != w {
+				match = false
+				break
+			}
+		}
+		if match {
+			return parts[4]
+		}
+	}
+	return ""
+}
+
+// splitPgpass splits a .pgpass line on colons, unescaping '\:' and '\\'.
+func splitPgpass(line string) []string {
+	var parts []string
+	var b bytes.Buffer
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == '\\' && i+1 < len(line):
+			i++
+			b.WriteByte(line[i])
+		case c == ':':
+			parts = append(parts, b.String())
+			b.Reset()
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return append(parts, b.String())
+}
+
+// describeConn returns where cc connects to, without the password.
+func describeConn(cc pgx.ConnConfig) string {
+	port := cc.Port
+	if port == 0 {
+		port = 5432
+	}
+	return fmt.Sprintf("%s@%s:%d/%s", cc.User, cc.Host, port, cc.Database)
+}
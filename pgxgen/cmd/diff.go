@@ -0,0 +1,74 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/sharonjl/pgxgen"
+	"github.com/spf13/cobra"
+)
+
+var diffSQL bool
+
+// diffCmd compares a schema snapshot to another snapshot, or to the schemas
+// read as for generating.
+var diffCmd = &cobra.Command{
+	Use:   "diff OLD.json [NEW.json]",
+	Short: "Report the differences between two schema snapshots",
+	Long: `Diff reports the tables, columns, keys, checks and types added, removed or
+changed between the snapshot OLD.json and NEW.json. Without NEW.json, OLD.json
+is compared to the database, or to what --ddl or --migrations describe, which
+catches drift from the schema code was generated against:
+
+  pgxgen diff schema.json --dsn $STAGING_URL
+
+With --sql the DDL taking OLD to NEW is written instead. As diff(1) does, it
+exits with status 1 when there are differences.`,
+	Args: cobra.RangeArgs(1, 2),
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := readSnapshot(args[0])
+		if err != nil {
+			panic("error reading schema: " + err.Error())
+		}
+		var to *pgxgen.PGData
+		if len(args) == 2 {
+			to, err = readSnapshot(args[1])
+		} else {
+			to, err = loadSchema()
+		}
+		if err != nil {
+			panic("error reading schema: " + err.Error())
+		}
+
+		changes := pgxgen.Diff(from, to)
+		for _, c := range changes {
+			if diffSQL {
+				fmt.Printf("-- %s\n%s\n\n", c, c.SQL)
+			} else {
+				fmt.Println(c)
+			}
+		}
+		if len(changes) > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	diffCmd.Flags().BoolVar(&diffSQL, "sql", false, "write the DDL taking OLD to NEW")
+	rootCmd.AddCommand(diffCmd)
+}
@@ -0,0 +1,61 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/sharonjl/pgxgen"
+	"github.com/spf13/cobra"
+)
+
+// inspectCmd writes the inspected schemas to a snapshot, which can be
+// committed and generated from later with --schema-snapshot.
+var inspectCmd = &cobra.Command{
+	Use:   "inspect",
+	Short: "Write the inspected schemas to a JSON snapshot",
+	Long: `Inspect reads the schemas from the database, or from --ddl files, and
+writes them as JSON to the file given with --out, or to stdout. Generating
+from the snapshot with --schema-snapshot gives the same code without
+connecting to the database:
+
+  pgxgen inspect --schema public --out schema.json
+  pgxgen --schema-snapshot schema.json --out ./gen`,
+	Run: func(cmd *cobra.Command, args []string) {
+		pgdata, err := loadSchema()
+		if err != nil {
+			panic("error reading schema: " + err.Error())
+		}
+
+		var b bytes.Buffer
+		if err := pgxgen.WriteSnapshot(&b, pgdata, schemas...); err != nil {
+			panic("error writing snapshot: " + err.Error())
+		}
+		if !cmd.Flags().Changed("out") {
+			os.Stdout.Write(b.Bytes())
+			return
+		}
+		outf := cmd.Flag("out").Value.String()
+		if err := ioutil.WriteFile(outf, b.Bytes(), 0644); err != nil {
+			panic("error writing snapshot: " + outf + ": " + err.Error())
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inspectCmd)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInspectWritesJSONWithConfigFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pgxgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	cfg, ddl := filepath.Join(dir, "pgxgen.yaml"), filepath.Join(dir, "schema.sql")
+	if err := ioutil.WriteFile(cfg, []byte("excludeTables: [schema_migrations]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(ddl, []byte("CREATE TABLE public.customers (id integer PRIMARY KEY);\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+	out := make(chan []byte)
+	go func() {
+		var b bytes.Buffer
+		io.Copy(&b, r)
+		out <- b.Bytes()
+	}()
+
+	rootCmd.SetArgs([]string{"inspect", "--config", cfg, "--ddl", ddl})
+	err = rootCmd.Execute()
+	w.Close()
+	os.Stdout = stdout
+	b := <-out
+	if err != nil {
+		t.Fatalf("inspect: %v", err)
+	}
+
+	var snapshot map[string]interface{}
+	if err := json.Unmarshal(b, &snapshot); err != nil {
+		t.Fatalf("inspect wrote invalid JSON: %v\n%s", err, b)
+	}
+}
@@ -0,0 +1,182 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/sharonjl/pgxgen"
+)
+
+var migrationsDir string
+
+// inspectMigrations creates a scratch database on the server the connection
+// flags point at, applies the migrations in dir to it, inspects it, and
+// drops it again, so the code is generated from the migrations rather than
+// whatever state a local database has drifted into. The connecting user
+// needs the CREATEDB privilege.
+func inspectMigrations(dir string) (*pgxgen.PGData, error) {
+	files, err := migrationFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	cc, err := connConfig()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := pgx.Connect(cc)
+	if err != nil {
+		return nil, errors.WithMessage(err, describeConn(cc))
+	}
+	defer conn.Close()
+
+	scratch := fmt.Sprintf("pgxgen_scratch_%d_%d", os.Getpid(), time.Now().Unix())
+	// template0 rather than template1, so objects added to template1 on the
+	// server don't end up in the generated code.
+	if _, err := conn.Exec("CREATE DATABASE " + scratch + " TEMPLATE template0"); err != nil {
+		return nil, errors.WithMessage(err, "creating scratch database")
+	}
+	defer func() {
+		if _, err := conn.Exec("DROP DATABASE IF EXISTS " + scratch); err != nil {
+			log.Printf("couldn't drop scratch database %s: %v", scratch, err)
+		}
+	}()
+
+	sc := cc
+	sc.Database = scratch
+	sconn, err := pgx.Connect(sc)
+	if err != nil {
+		return nil, errors.WithMessage(err, describeConn(sc))
+	}
+	defer sconn.Close()
+
+	for _, f := range files {
+		sql, err := migrationSQL(f)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := sconn.Exec(sql); err != nil {
+			return nil, errors.WithMessage(err, "applying "+migrationPosition(f, sql, err))
+		}
+	}
+
+	pgdata, err := pgxgen.Inspect(sconn, schemas...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "inspecting scratch database")
+	}
+	return pgdata, nil
+}
+
+// migrationFiles returns the .sql files in dir in the order they apply.
+// Down migrations, named *.down.sql as golang-migrate and similar tools
+// do, are left out.
+func migrationFiles(dir string) ([]string, error) {
+	ff, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.WithMessage(err, "reading migrations")
+	}
+	var names []string
+	for _, f := range ff {
+		n := f.Name()
+		if f.IsDir() || !strings.HasSuffix(n, ".sql") || strings.HasSuffix(n, ".down.sql") {
+			continue
+		}
+		names = append(names, n)
+	}
+	if len(names) == 0 {
+		return nil, errors.Errorf("no migrations in %s", dir)
+	}
+	sort.Slice(names, func(i, j int) bool { return migrationLess(names[i], names[j]) })
+
+	files := make([]string, len(names))
+	for k, n := range names {
+		files[k] = filepath.Join(dir, n)
+	}
+	return files, nil
+}
+
+// migrationSQL returns the SQL of the migration file f which migrates up.
+// Goose keeps both directions in one file, and everything from its
+// '-- +goose Down' line on is left out; the lines before are kept as they
+// are, so errors are reported at the line of the file.
+func migrationSQL(f string) (string, error) {
+	b, err := ioutil.ReadFile(f)
+	if err != nil {
+		return "", errors.WithMessage(err, "reading migration")
+	}
+	sql := string(b)
+	for i := 0; i < len(sql); {
+		end := strings.IndexByte(sql[i:], '\n') + 1
+		if end == 0 {
+			end = len(sql) - i
+		}
+		line := strings.TrimSpace(sql[i : i+end])
+		if strings.HasPrefix(line, "--") && strings.HasPrefix(strings.TrimSpace(line[2:]), "+goose Down") {
+			return sql[:i], nil
+		}
+		i += end
+	}
+	return sql, nil
+}
+
+// migrationLess orders names by their leading version number, so that
+// '2_b.sql' applies before '10_a.sql', and otherwise by name. Flyway's
+// 'V2__b.sql' style is numbered too.
+func migrationLess(a, b string) bool {
+	va, ra := leadingNumber(a)
+	vb, rb := leadingNumber(b)
+	if ra && rb && va != vb {
+		return va < vb
+	}
+	return a < b
+}
+
+func leadingNumber(s string) (uint64, bool) {
+	if len(s) > 1 && (s[0] == 'V' || s[0] == 'v') {
+		s = s[1:]
+	}
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	v, err := strconv.ParseUint(s[:i], 10, 64)
+	return v, err == nil
+}
+
+// migrationPosition names file, and the line of sql the server reported the
+// error at when it did.
+func migrationPosition(file, sql string, err error) string {
+	pe, ok := err.(pgx.PgError)
+	if !ok || pe.Position <= 0 {
+		return file
+	}
+	// Position counts characters, not bytes, from 1.
+	r := []rune(sql)
+	if int(pe.Position) > len(r) {
+		return file
+	}
+	return fmt.Sprintf("%s:%d", file, strings.Count(string(r[:pe.Position-1]), "\n")+1)
+}
@@ -0,0 +1,77 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMigrationSQL(t *testing.T) {
+	tests := []struct {
+		name, src, want string
+	}{
+		{
+			name: "goose",
+			src: `-- +goose Up
+CREATE TABLE customers (id int);
+-- +goose StatementBegin
+CREATE FUNCTION f() RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql;
+-- +goose StatementEnd
+
+-- +goose Down
+DROP FUNCTION f();
+DROP TABLE customers;
+`,
+			want: `-- +goose Up
+CREATE TABLE customers (id int);
+-- +goose StatementBegin
+CREATE FUNCTION f() RETURNS int AS $$ SELECT 1 $$ LANGUAGE sql;
+-- +goose StatementEnd
+
+`,
+		},
+		{
+			name: "goose without a space",
+			src:  "--+goose Up\r\nCREATE TABLE t (id int);\r\n  --+goose Down\r\nDROP TABLE t;",
+			want: "--+goose Up\r\nCREATE TABLE t (id int);\r\n",
+		},
+		{
+			name: "plain",
+			src:  "CREATE TABLE t (id int); -- +goose Down isn't at the start of the line\n",
+			want: "CREATE TABLE t (id int); -- +goose Down isn't at the start of the line\n",
+		},
+	}
+	dir, err := ioutil.TempDir("", "pgxgen")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	for _, tt := range tests {
+		f := filepath.Join(dir, "1_"+tt.name+".sql")
+		if err := ioutil.WriteFile(f, []byte(tt.src), 0644); err != nil {
+			t.Fatal(err)
+		}
+		got, err := migrationSQL(f)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: migrationSQL =\n%q\nwant\n%q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"runtime"
+	"sync"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// renderJob is a file generated from a template.
+type renderJob struct {
+	filename string
+	template string
+	data     interface{}
+}
+
+// renderFiles executes jobs on runtime.NumCPU() workers. A file is only
+// written once its template executed successfully. When jobs fail, the error
+// of the first one in jobs is returned.
+func renderFiles(tpl *template.Template, jobs []renderJob) error {
+	errs := make([]error, len(jobs))
+	next := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				errs[i] = renderFile(tpl, jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderFile(tpl *template.Template, job renderJob) error {
+	var b bytes.Buffer
+	if err := tpl.ExecuteTemplate(&b, job.template, job.data); err != nil {
+		return errors.WithMessage(err, "error executing template: "+job.filename)
+	}
+	if err := ioutil.WriteFile(job.filename, b.Bytes(), 0666); err != nil {
+		return errors.WithMessage(err, "error creating file: "+job.filename)
+	}
+	return nil
+}
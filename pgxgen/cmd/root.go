@@ -16,15 +16,16 @@ package cmd
 
 import (
 	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 	"text/template"
 
-	"github.com/jackc/pgx"
 	"github.com/mitchellh/go-homedir"
 	"github.com/pelletier/go-toml"
 	"github.com/sharonjl/pgxgen"
@@ -35,10 +36,11 @@ import (
 )
 
 var cfgFile string
-var dbHost string
-var dbUser string
-var dbPassword string
-var dbName string
+var schemas []string
+var schemaLayout string
+var domainTypes bool
+var templateDir string
+var partitionHelpers bool
 
 // rootCmd represents the base command when called without any subcommands
 var rootCmd = &cobra.Command{
@@ -80,13 +82,42 @@ func init() {
 	// Cobra supports persistent flags, which, if defined here,
 	// will be global for your application.
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "config file (default is $HOME/.pgxgen.yaml)")
-	rootCmd.PersistentFlags().StringVar(&dbHost, "dbHost", "localhost", "connection hostname")
-	rootCmd.PersistentFlags().StringVar(&dbUser, "dbUser", "sharon", "connecting user")
-	rootCmd.PersistentFlags().StringVar(&dbPassword, "dbPassword", "", "password for connecting user")
-	rootCmd.PersistentFlags().StringVar(&dbName, "dbName", "", "database to connect to")
+	rootCmd.PersistentFlags().StringVar(&dbDSN, "dsn", "", "connection URL or key/value string, e.g. 'postgres://user@host:5432/db?sslmode=require' (default is $DATABASE_URL)")
+	rootCmd.PersistentFlags().StringVar(&dbHost, "dbHost", "", "connection hostname (default is $PGHOST, or localhost)")
+	rootCmd.PersistentFlags().Uint16Var(&dbPort, "dbPort", 0, "connection port (default is $PGPORT, or 5432)")
+	rootCmd.PersistentFlags().StringVar(&dbUser, "dbUser", "", "connecting user (default is $PGUSER, or the current user)")
+	rootCmd.PersistentFlags().StringVar(&dbPassword, "dbPassword", "", "password for connecting user (default is $PGPASSWORD, or from the password file)")
+	rootCmd.PersistentFlags().StringVar(&dbName, "dbName", "", "database to connect to (default is $PGDATABASE)")
+	rootCmd.PersistentFlags().StringVar(&dbSSLMode, "sslmode", "", "disable, allow, prefer, require, verify-ca or verify-full (default is $PGSSLMODE, or prefer)")
+	rootCmd.PersistentFlags().StringVar(&dbSSLRootCert, "sslrootcert", "", "file of certificate authorities to verify the server with (default is $PGSSLROOTCERT)")
+	rootCmd.PersistentFlags().StringVar(&dbSSLCert, "sslcert", "", "client certificate file (default is $PGSSLCERT)")
+	rootCmd.PersistentFlags().StringVar(&dbSSLKey, "sslkey", "", "client key file (default is $PGSSLKEY)")
+	rootCmd.PersistentFlags().StringVar(&snapshotFile, "schema-snapshot", "", "generate from a schema snapshot written by 'pgxgen inspect', instead of connecting to the database")
+	rootCmd.PersistentFlags().StringSliceVar(&ddlFiles, "ddl", nil, "generate from these SQL files, e.g. the output of 'pg_dump --schema-only', instead of connecting to the database")
+	rootCmd.PersistentFlags().StringVar(&migrationsDir, "migrations", "", "generate from the .sql files of this directory, applied in order to a scratch database created and dropped on the server")
+	rootCmd.PersistentFlags().StringSliceVar(&schemas, "schema", []string{"public"}, "schemas to inspect, repeated or comma separated")
+	rootCmd.PersistentFlags().StringVar(&schemaLayout, "schemaLayout", "package", "output of multiple schemas: 'package' generates each schema into its own directory, 'prefix' prefixes names outside the first schema with their schema")
+	rootCmd.PersistentFlags().BoolVar(&domainTypes, "domainTypes", false, "generate a distinct type per domain instead of using its base type")
+	rootCmd.PersistentFlags().StringSlice("includeTables", nil, "generate only tables matching these globs or /regexps/")
+	rootCmd.PersistentFlags().StringSlice("excludeTables", nil, "skip tables matching these globs or /regexps/, e.g. schema_migrations")
+	rootCmd.PersistentFlags().StringSlice("includeColumns", nil, "generate only columns matching these globs or /regexps/, as column, table.column or schema.table.column")
+	rootCmd.PersistentFlags().StringSlice("excludeColumns", nil, "leave out columns matching these globs or /regexps/, as column, table.column or schema.table.column")
+	rootCmd.PersistentFlags().StringSlice("includeEnums", nil, "generate only enums matching these globs or /regexps/")
+	rootCmd.PersistentFlags().StringSlice("excludeEnums", nil, "skip enums matching these globs or /regexps/; their columns are generated as text")
+	rootCmd.PersistentFlags().StringSlice("includeFunctions", nil, "generate wrappers only for functions and procedures matching these globs or /regexps/")
+	rootCmd.PersistentFlags().StringSlice("excludeFunctions", nil, "skip functions and procedures matching these globs or /regexps/")
+	rootCmd.PersistentFlags().StringSlice("includeSequences", nil, "generate helpers only for sequences matching these globs or /regexps/")
+	rootCmd.PersistentFlags().StringSlice("excludeSequences", nil, "skip sequences matching these globs or /regexps/")
+	rootCmd.PersistentFlags().Bool("childTables", false, "also generate partitions, and tables inheriting from a generated table")
+	for _, f := range []string{"includeTables", "excludeTables", "includeColumns", "excludeColumns", "includeEnums", "excludeEnums", "includeFunctions", "excludeFunctions", "includeSequences", "excludeSequences", "childTables"} {
+		// The filters can also be set in the config file.
+		viper.BindPFlag(f, rootCmd.PersistentFlags().Lookup(f))
+	}
+	rootCmd.PersistentFlags().BoolVar(&partitionHelpers, "partitionHelpers", false, "generate methods creating the next partition of tables partitioned by range of a date or timestamp column")
 	rootCmd.PersistentFlags().String("package", "dbmodel", "package name")
 	rootCmd.PersistentFlags().String("query", "config.toml", "query definition file")
 	rootCmd.PersistentFlags().String("out", ".", "output")
+	rootCmd.PersistentFlags().StringVar(&templateDir, "templates", "", "directory of .tpl files replacing the built-in templates of the same name")
 	// Cobra also supports local flags, which will only run
 	// when this action is called directly.
 	rootCmd.Flags().BoolP("toggle", "t", false, "Help message for toggle")
@@ -112,9 +143,28 @@ func initConfig() {
 
 	viper.AutomaticEnv() // read in environment variables that match
 
-	// If a config file is found, read it in.
+	// If a config file is found, read it in. Stdout is left to the output
+	// of commands, such as the snapshot written by inspect.
 	if err := viper.ReadInConfig(); err == nil {
-		fmt.Println("Using config file:", viper.ConfigFileUsed())
+		fmt.Fprintln(os.Stderr, "Using config file:", viper.ConfigFileUsed())
+	}
+}
+
+// selector returns the table, column and enum filters set by flags or in the
+// config file.
+func selector() *pgxgen.Selector {
+	return &pgxgen.Selector{
+		IncludeTables:    viper.GetStringSlice("includeTables"),
+		ExcludeTables:    viper.GetStringSlice("excludeTables"),
+		IncludeColumns:   viper.GetStringSlice("includeColumns"),
+		ExcludeColumns:   viper.GetStringSlice("excludeColumns"),
+		IncludeEnums:     viper.GetStringSlice("includeEnums"),
+		ExcludeEnums:     viper.GetStringSlice("excludeEnums"),
+		IncludeFunctions: viper.GetStringSlice("includeFunctions"),
+		ExcludeFunctions: viper.GetStringSlice("excludeFunctions"),
+		IncludeSequences: viper.GetStringSlice("includeSequences"),
+		ExcludeSequences: viper.GetStringSlice("excludeSequences"),
+		ChildTables:      viper.GetBool("childTables"),
 	}
 }
 
@@ -134,20 +184,14 @@ func qbRunFn(gendir string, cmd *cobra.Command, args []string) {
 	// Package name
 	pkgName := "builder"
 
-	conn, err := pgx.Connect(pgx.ConnConfig{
-		Host:     dbHost,
-		User:     dbUser,
-		Password: dbPassword,
-		Database: dbName,
-	})
+	ins, err := loadSchema()
 	if err != nil {
-		panic("couldn't connect to db: " + err.Error())
+		panic("error reading schema: " + err.Error())
 	}
-
-	ins, err := pgxgen.Inspect(conn, "public")
-	if err != nil {
-		panic("error inspecting db: " + err.Error())
+	if err := selector().Apply(ins); err != nil {
+		panic("error filtering tables: " + err.Error())
 	}
+	pgxgen.PrefixSchemas(ins, schemas[0])
 
 	tmpl, err := template.New("a").Funcs(template.FuncMap{
 		"exported": func(s ...string) string {
@@ -189,52 +233,41 @@ func qbRunFn(gendir string, cmd *cobra.Command, args []string) {
 	// }
 
 	// Write tables
+	var jobs []renderJob
 	for _, en := range ins.Tables {
-		filename := filepath.Join(outdir, "qb_table_"+strings.ToLower(en.Name)+".go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tmpl.ExecuteTemplate(f, "table_qb.tpl",
-			struct {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(outdir, "qb_table_"+strings.ToLower(en.Prefix+en.Name)+".go"),
+			template: "table_qb.tpl",
+			data: struct {
 				PackageName string
 				Table       *pgxgen.Table
 			}{
 				PackageName: pkgName,
 				Table:       en,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+			},
+		})
 	}
 
 	// Write utils file which contains helpers
-	filename := filepath.Join(outdir, "qb_utils.go")
-	f, err := os.Create(filename)
-	if err != nil {
-		f.Close()
-		panic("error creating file: " + filename + ": " + err.Error())
-	}
 	var tables []*pgxgen.Table
 	for _, t := range ins.Tables {
 		tables = append(tables, t)
 	}
-	err = tmpl.ExecuteTemplate(f, "utils_qb.tpl",
-		struct {
+	jobs = append(jobs, renderJob{
+		filename: filepath.Join(outdir, "qb_utils.go"),
+		template: "utils_qb.tpl",
+		data: struct {
 			PackageName string
 			Tables      []*pgxgen.Table
 		}{
 			PackageName: pkgName,
 			Tables:      tables,
-		})
-	if err != nil {
-		f.Close()
-		panic("error executing template: " + filename + ": " + err.Error())
+		},
+	})
+
+	if err := renderFiles(tmpl, jobs); err != nil {
+		panic(err.Error())
 	}
-	f.Close()
 
 	// Format output
 	out, err := exec.Command("sh", "-c", "goimports -w "+filepath.Join(outdir, "*.go")).Output()
@@ -244,8 +277,71 @@ func qbRunFn(gendir string, cmd *cobra.Command, args []string) {
 }
 
 func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
+	// Read DB, or DDL
+	pgdata, err := loadSchema()
+	if err != nil {
+		panic("error reading schema: " + err.Error())
+	}
+	generateModels(gendir, cmd, pgdata)
+}
 
-	// Output directory
+// generateModels generates the code for pgdata, as read by loadSchema, into
+// gendir.
+func generateModels(gendir string, cmd *cobra.Command, pgdata *pgxgen.PGData) {
+	// Read QueryDefinition defn
+	queryFile := cmd.Flag("query").Value.String()
+	queryFile, _ = filepath.Abs(queryFile)
+
+	queryDefs, err := toml.LoadFile(queryFile)
+	if err != nil {
+		panic("error could not read query defns: " + queryFile + ": " + err.Error())
+	}
+
+	if err := selector().Apply(pgdata); err != nil {
+		panic("error filtering tables: " + err.Error())
+	}
+
+	// Read query config
+	queryDoc := pgxgen.QueryDefinitions{}
+	err = queryDefs.Unmarshal(&queryDoc)
+	if err != nil {
+		panic("error unmarshalling queries: " + err.Error())
+	}
+
+	// Mistakes in the definitions don't stop generating, so that the rest of
+	// the queries still are.
+	for _, err := range pgxgen.ValidateQueryDefinitions(queryDoc, *pgdata) {
+		log.Printf("%s: %v", filepath.Base(queryFile), err)
+	}
+
+	if len(schemas) > 1 && schemaLayout == "prefix" {
+		pgxgen.PrefixSchemas(pgdata, schemas[0])
+	}
+	if domainTypes {
+		pgxgen.RegisterDomainTypes(pgdata)
+	}
+	if err := pgdata.CheckTypes(); err != nil {
+		panic("error mapping types: " + err.Error())
+	}
+
+	modelPkgName := cmd.Flag("package").Value.String()
+	switch {
+	case len(schemas) == 1 || schemaLayout == "prefix":
+		writeModels(gendir, modelPkgName, pgdata, queryDoc)
+	case schemaLayout == "package":
+		for _, s := range pgdata.Schemas() {
+			sub := pgdata.Schema(s)
+			pgxgen.RegisterTypes(sub)
+			writeModels(filepath.Join(gendir, s), modelPkgName, sub, queryDoc)
+		}
+	default:
+		panic("unknown schema layout: " + schemaLayout)
+	}
+}
+
+// writeModels generates the models, datastore and types packages of pgdata
+// into gendir.
+func writeModels(gendir string, modelPkgName string, pgdata *pgxgen.PGData, queryDoc pgxgen.QueryDefinitions) {
 	// Output directory
 	outf := filepath.Join(gendir)
 	outdir, err := filepath.Abs(outf)
@@ -258,7 +354,7 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 		panic("error creating output directory: " + outf + ": " + err.Error())
 	}
 
-	modelDir := filepath.Join(outdir, cmd.Flag("package").Value.String())
+	modelDir := filepath.Join(outdir, modelPkgName)
 	err = os.MkdirAll(modelDir, os.ModePerm)
 	if err != nil {
 		panic("error creating models directory: " + modelDir + ": " + err.Error())
@@ -281,40 +377,9 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 	srcPath := []rune(filepath.Join(os.Getenv("GOPATH"), "src"))
 	importPath := string([]rune(outdir)[len(srcPath)+1:])
 
-	modelPkgName := filepath.Base(modelDir)
-
-	// Read QueryDefinition defn
-	queryFile := cmd.Flag("query").Value.String()
-	queryFile, _ = filepath.Abs(queryFile)
-
-	queryDefs, err := toml.LoadFile(queryFile)
-	if err != nil {
-		panic("error could not read query defns: " + queryFile + ": " + err.Error())
-	}
-
-	// Read DB
-	conn, err := pgx.Connect(pgx.ConnConfig{
-		Host:     dbHost,
-		User:     dbUser,
-		Password: dbPassword,
-		Database: dbName,
-	})
-	if err != nil {
-		panic("couldn't connect to db: " + err.Error())
-	}
-
-	pgdata, err := pgxgen.Inspect(conn, "public")
-	if err != nil {
-		panic("error inspecting db: " + err.Error())
-	}
-
-	// Read query config
-	queryDoc := pgxgen.QueryDefinitions{}
-	err = queryDefs.Unmarshal(&queryDoc)
-	if err != nil {
-		panic("error unmarshalling queries: " + err.Error())
-	}
 	queries := pgxgen.ProcessQueryDefinitions(queryDoc, *pgdata)
+	relations := pgxgen.ProcessRelations(*pgdata)
+	routines := pgxgen.ProcessFunctions(*pgdata, queries)
 
 	tpl := template.New("model").Funcs(template.FuncMap{
 		"exported": func(s ...string) string {
@@ -336,6 +401,21 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 		log.Print(filepath.Base(name))
 		tpl, _ = tpl.New(filepath.Base(name)).Parse(string(tmpl.MustAsset(name)))
 	}
+	if templateDir != "" {
+		files, err := filepath.Glob(filepath.Join(templateDir, "*.tpl"))
+		if err != nil {
+			panic("error reading templates: " + templateDir + ": " + err.Error())
+		}
+		for _, f := range files {
+			b, err := ioutil.ReadFile(f)
+			if err != nil {
+				panic("error reading template: " + err.Error())
+			}
+			if tpl, err = tpl.New(filepath.Base(f)).Parse(string(b)); err != nil {
+				panic("error parsing template: " + err.Error())
+			}
+		}
+	}
 	//tpl, _ = tpl.New("enum.tpl").Parse(string(tmpl.MustAsset("../tmpl/enum.tpl")))
 	//tpl, _ = tpl.New("table.tpl").Parse(string(tmpl.MustAsset("../tmpl/table.tpl")))
 	//tpl, _ = tpl.New("table_fn.tpl").Parse(string(tmpl.MustAsset("../tmpl/table_fn.tpl")))
@@ -345,16 +425,16 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 	//tpl, _ = tpl.New("postgres.tpl").Parse(string(tmpl.MustAsset("../tmpl/postgres.tpl")))
 	//tpl, _ = tpl.New("datastore_keys.tpl").Parse(string(tmpl.MustAsset("../tmpl/datastore_keys.tpl")))
 
+	// Every file depends only on its template and data, so they are rendered
+	// concurrently; the output is the same whatever order they finish in.
+	var jobs []renderJob
+
 	// Write enums
 	for _, en := range pgdata.Enums {
-		filename := filepath.Join(modelDir, strings.ToLower(en.Name)+".pgxgen.go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, "enum.tpl",
-			struct {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(modelDir, strings.ToLower(en.Prefix+en.Name)+".pgxgen.go"),
+			template: "enum.tpl",
+			data: struct {
 				PackageName string
 				ImportPath  string
 				Enum        *pgxgen.Enum
@@ -362,25 +442,89 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 				PackageName: modelPkgName,
 				ImportPath:  importPath,
 				Enum:        en,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
+			},
+		})
+	}
+
+	// Write domains
+	for _, d := range pgdata.Domains {
+		if !d.HasGoType() {
+			continue
 		}
-		f.Close()
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(modelDir, strings.ToLower(d.Prefix+d.Name)+".pgxgen.go"),
+			template: "domain.tpl",
+			data: struct {
+				PackageName string
+				ImportPath  string
+				Domain      *pgxgen.Domain
+			}{
+				PackageName: modelPkgName,
+				ImportPath:  importPath,
+				Domain:      d,
+			},
+		})
+	}
+
+	// Write composite types
+	for _, ct := range pgdata.Composites {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(modelDir, strings.ToLower(ct.Prefix+ct.Name)+".pgxgen.go"),
+			template: "composite.tpl",
+			data: struct {
+				PackageName string
+				ImportPath  string
+				Composite   *pgxgen.Composite
+			}{
+				PackageName: modelPkgName,
+				ImportPath:  importPath,
+				Composite:   ct,
+			},
+		})
+	}
+	if len(pgdata.Composites) > 0 {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(modelDir, "composite.pgxgen.go"),
+			template: "composite_text.tpl",
+			data: struct {
+				PackageName string
+			}{
+				PackageName: modelPkgName,
+			},
+		})
+	}
+
+	// Write the time of day types, which pgtype lacks
+	if pgdata.Uses("time", "timetz") {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(modelDir, "time.pgxgen.go"),
+			template: "time.tpl",
+			data: struct {
+				PackageName string
+			}{
+				PackageName: modelPkgName,
+			},
+		})
 	}
 
+	// Write check errors, returned by the generated Validate methods
+	jobs = append(jobs, renderJob{
+		filename: filepath.Join(modelDir, "check.pgxgen.go"),
+		template: "check.tpl",
+		data: struct {
+			PackageName string
+		}{
+			PackageName: modelPkgName,
+		},
+	})
+
 	// Write tables
 	for _, en := range pgdata.Tables {
 		// Model
-		filename := filepath.Join(modelDir, strings.ToLower(en.Name)+".pgxgen.go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, "table.tpl",
-			struct {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(modelDir, strings.ToLower(en.Prefix+en.Name)+".pgxgen.go"),
+			template: "table.tpl",
+			data: struct {
 				PackageName string
 				ImportPath  string
 				Table       *pgxgen.Table
@@ -388,21 +532,13 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 				PackageName: modelPkgName,
 				ImportPath:  importPath,
 				Table:       en,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+			},
+		})
 
-		filename = filepath.Join(postgresImplDir, strings.ToLower(en.Name)+".pgxgen.go")
-		f, err = os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, "table_fn.tpl",
-			struct {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(postgresImplDir, strings.ToLower(en.Prefix+en.Name)+".pgxgen.go"),
+			template: "table_fn.tpl",
+			data: struct {
 				PackageName      string
 				ImportPath       string
 				ModelPackageName string
@@ -412,111 +548,140 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 				ImportPath:       importPath,
 				PackageName:      "postgres",
 				Table:            en,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+			},
+		})
 	}
 
-	// Write queries
-	{
-		filename := filepath.Join(postgresImplDir, "queries.pgxgen.go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
+	// Write partition helpers
+	var partitioned []*pgxgen.Table
+	for _, t := range pgdata.Tables {
+		if partitionHelpers && t.TimePartitionColumn() != nil {
+			partitioned = append(partitioned, t)
 		}
-		err = tpl.ExecuteTemplate(f, "queries.tpl",
-			struct {
-				PackageName      string
-				ImportPath       string
-				ModelPackageName string
-				Queries          []pgxgen.Query
+	}
+	if len(partitioned) > 0 {
+		sort.Slice(partitioned, func(i, j int) bool { return partitioned[i].QualifiedName() < partitioned[j].QualifiedName() })
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(postgresImplDir, "partitions.pgxgen.go"),
+			template: "partitions.tpl",
+			data: struct {
+				PackageName string
+				Tables      []*pgxgen.Table
 			}{
-				PackageName:      "postgres",
-				ModelPackageName: modelPkgName,
-				ImportPath:       importPath,
-				Queries:          queries,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+				PackageName: "postgres",
+				Tables:      partitioned,
+			},
+		})
 	}
-	{
-		filename := filepath.Join(postgresImplDir, "postgres.pgxgen.go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, "postgres.tpl",
-			struct {
+
+	// Write function wrappers
+	if len(routines) > 0 {
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(postgresImplDir, "functions.pgxgen.go"),
+			template: "functions.tpl",
+			data: struct {
 				PackageName      string
 				ImportPath       string
 				ModelPackageName string
-				Queries          []pgxgen.Query
+				Routines         []pgxgen.Routine
 			}{
 				PackageName:      "postgres",
 				ModelPackageName: modelPkgName,
 				ImportPath:       importPath,
-				Queries:          queries,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+				Routines:         routines,
+			},
+		})
 	}
 
-	{
-		filename := filepath.Join(datastoredir, "datastore.pgxgen.go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, "datastore.tpl",
-			struct {
+	// Write sequence helpers
+	var sequences []*pgxgen.Sequence
+	for _, sq := range pgdata.Sequences {
+		sequences = append(sequences, sq)
+	}
+	if len(sequences) > 0 {
+		sort.Slice(sequences, func(i, j int) bool { return sequences[i].ExportedName() < sequences[j].ExportedName() })
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(postgresImplDir, "sequences.pgxgen.go"),
+			template: "sequences.tpl",
+			data: struct {
 				PackageName string
-				ImportPath  string
+				Sequences   []*pgxgen.Sequence
 			}{
-				PackageName: "datastore",
-				ImportPath:  importPath,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+				PackageName: "postgres",
+				Sequences:   sequences,
+			},
+		})
+	}
 
-		filename = filepath.Join(datastoredir, "keys.pgxgen.go")
-		f, err = os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, "datastore_keys.tpl",
-			struct {
+	// Write queries
+	queriesData := struct {
+		PackageName      string
+		ImportPath       string
+		ModelPackageName string
+		Queries          []pgxgen.Query
+	}{
+		PackageName:      "postgres",
+		ModelPackageName: modelPkgName,
+		ImportPath:       importPath,
+		Queries:          queries,
+	}
+	jobs = append(jobs,
+		renderJob{
+			filename: filepath.Join(postgresImplDir, "queries.pgxgen.go"),
+			template: "queries.tpl",
+			data:     queriesData,
+		},
+		renderJob{
+			filename: filepath.Join(postgresImplDir, "relations.pgxgen.go"),
+			template: "relations.tpl",
+			data: struct {
 				PackageName      string
 				ImportPath       string
 				ModelPackageName string
-				Queries          []pgxgen.Query
+				Relations        []pgxgen.Relation
 			}{
-				PackageName:      "datastore",
+				PackageName:      "postgres",
 				ModelPackageName: modelPkgName,
 				ImportPath:       importPath,
-				Queries:          queries,
-			})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
-	}
+				Relations:        relations,
+			},
+		},
+		renderJob{
+			filename: filepath.Join(postgresImplDir, "postgres.pgxgen.go"),
+			template: "postgres.tpl",
+			data:     queriesData,
+		},
+		renderJob{
+			filename: filepath.Join(postgresImplDir, "schema.pgxgen.go"),
+			template: "schema.tpl",
+			data: struct {
+				PackageName string
+				Fingerprint string
+				Data        *pgxgen.PGData
+			}{
+				PackageName: "postgres",
+				Fingerprint: pgdata.Fingerprint(),
+				Data:        pgdata,
+			},
+		},
+		renderJob{
+			filename: filepath.Join(datastoredir, "datastore.pgxgen.go"),
+			template: "datastore.tpl",
+			data: struct {
+				PackageName string
+				ImportPath  string
+			}{
+				PackageName: "datastore",
+				ImportPath:  importPath,
+			},
+		},
+	)
+	queriesData.PackageName = "datastore"
+	jobs = append(jobs, renderJob{
+		filename: filepath.Join(datastoredir, "keys.pgxgen.go"),
+		template: "datastore_keys.tpl",
+		data:     queriesData,
+	})
 
 	typeFiles := []string{
 		"nullzero/null",
@@ -525,28 +690,25 @@ func modelRunFn(gendir string, cmd *cobra.Command, args []string) {
 		"types",
 	}
 	for _, fn := range typeFiles {
-		filename := filepath.Join(typesdir, fn+".pgxgen.go")
-		f, err := os.Create(filename)
-		if err != nil {
-			f.Close()
-			panic("error creating file: " + filename + ": " + err.Error())
-		}
-		err = tpl.ExecuteTemplate(f, filepath.Base(fn)+".tpl", struct {
-			PackageName      string
-			ModelPackageName string
-			ImportPath       string
-			Data             *pgxgen.PGData
-		}{
-			ModelPackageName: modelPkgName,
-			ImportPath:       importPath,
-			PackageName:      "types",
-			Data:             pgdata,
+		jobs = append(jobs, renderJob{
+			filename: filepath.Join(typesdir, fn+".pgxgen.go"),
+			template: filepath.Base(fn) + ".tpl",
+			data: struct {
+				PackageName      string
+				ModelPackageName string
+				ImportPath       string
+				Data             *pgxgen.PGData
+			}{
+				ModelPackageName: modelPkgName,
+				ImportPath:       importPath,
+				PackageName:      "types",
+				Data:             pgdata,
+			},
 		})
-		if err != nil {
-			f.Close()
-			panic("error executing template: " + filename + ": " + err.Error())
-		}
-		f.Close()
+	}
+
+	if err := renderFiles(tpl, jobs); err != nil {
+		panic(err.Error())
 	}
 
 	// Write utils file which contains helpers
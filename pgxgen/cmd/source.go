@@ -0,0 +1,106 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+
+	"github.com/pkg/errors"
+	"github.com/sharonjl/pgxgen"
+	"github.com/spf13/cobra"
+)
+
+var ddlFiles []string
+var snapshotFile string
+
+// schemasGiven is set when --schema is given, rather than defaulted.
+var schemasGiven bool
+
+func init() {
+	cobra.OnInitialize(func() {
+		schemasGiven = rootCmd.PersistentFlags().Changed("schema")
+	})
+}
+
+// loadSchema reads the schemas to generate code for, from the snapshot given
+// with --schema-snapshot, the DDL files given with --ddl, a scratch database
+// the --migrations are applied to, or else from the database.
+func loadSchema() (*pgxgen.PGData, error) {
+	if snapshotFile != "" {
+		return readSnapshot(snapshotFile)
+	}
+	if len(ddlFiles) > 0 {
+		return readDDL(ddlFiles)
+	}
+	if migrationsDir != "" {
+		return inspectMigrations(migrationsDir)
+	}
+	return inspectDB()
+}
+
+// inspectDB reads the schemas from the database.
+func inspectDB() (*pgxgen.PGData, error) {
+	conn, err := connect()
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't connect to db")
+	}
+	defer conn.Close()
+	pgdata, err := pgxgen.Inspect(conn, schemas...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "inspecting db")
+	}
+	return pgdata, nil
+}
+
+// readSnapshot reads a schema snapshot written by 'pgxgen inspect'. Unless
+// --schema is given, all schemas of the snapshot are read.
+func readSnapshot(file string) (*pgxgen.PGData, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, errors.WithMessage(err, "opening schema snapshot")
+	}
+	defer f.Close()
+	var only []string
+	if schemasGiven {
+		only = schemas
+	}
+	s, err := pgxgen.ReadSnapshot(f, only...)
+	if err != nil {
+		return nil, errors.WithMessage(err, file)
+	}
+	schemas = s.Schemas
+	return s.PGData, nil
+}
+
+// readDDL parses the files as one script, in the order given.
+func readDDL(files []string) (*pgxgen.PGData, error) {
+	var src bytes.Buffer
+	for _, f := range files {
+		b, err := ioutil.ReadFile(f)
+		if err != nil {
+			return nil, errors.WithMessage(err, "reading DDL")
+		}
+		src.Write(b)
+		// A file may end without a semicolon.
+		src.WriteString("\n;\n")
+	}
+	pgdata, err := pgxgen.ParseDDL(src.String(), schemas...)
+	if err != nil {
+		return nil, errors.WithMessage(err, "parsing DDL")
+	}
+	return pgdata, nil
+}
@@ -0,0 +1,282 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/jackc/pgx"
+	"github.com/pkg/errors"
+	"github.com/sharonjl/pgxgen"
+	"github.com/spf13/cobra"
+)
+
+var watchDB bool
+var watchInstallTrigger bool
+var watchInterval time.Duration
+
+// ddlChannel is the channel the event trigger notifies of DDL commands on.
+const ddlChannel = "pgxgen_ddl"
+
+// The event trigger installed by --install-trigger. Creating event triggers
+// needs superuser rights, which is why installing it is optional; without
+// it, changes are found by polling every --interval, or by migrations
+// running NOTIFY pgxgen_ddl themselves.
+const installDDLTrigger = `
+CREATE OR REPLACE FUNCTION pgxgen_notify_ddl() RETURNS event_trigger LANGUAGE plpgsql AS $$
+BEGIN
+  PERFORM pg_notify('` + ddlChannel + `', tg_tag);
+END;
+$$;
+DROP EVENT TRIGGER IF EXISTS pgxgen_notify_ddl;
+CREATE EVENT TRIGGER pgxgen_notify_ddl ON ddl_command_end EXECUTE PROCEDURE pgxgen_notify_ddl();
+`
+
+// quietPeriod is how long notifications or file events must stop for before
+// regenerating, so a migration of many statements, or an editor writing a
+// file in several steps, regenerates once.
+const quietPeriod = 500 * time.Millisecond
+
+var watchCmd = &cobra.Command{
+	Use:   "watch",
+	Short: "Regenerate whenever the queries, templates or schema change",
+	Long: `Watch generates the code, then regenerates it whenever the --query file or a
+template of --templates changes. Mistakes in the query definitions, and other
+errors, are printed rather than ending the watch, so it can run while they are
+being edited.
+
+With --db it also listens for DDL notifications from the database, inspects it
+again and regenerates whenever the schemas actually changed. The notifications
+come from an event trigger, installed with --install-trigger by a superuser,
+or from migrations running 'NOTIFY pgxgen_ddl'. With --interval the schemas
+are also inspected periodically.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if watchDB && (snapshotFile != "" || len(ddlFiles) > 0 || migrationsDir != "") {
+			panic("--db watches the database; it can't be used with --schema-snapshot, --ddl or --migrations")
+		}
+		outf := cmd.Flag("out").Value.String()
+		gendir, err := filepath.Abs(outf)
+		if err != nil {
+			panic("output directory: " + outf + ": " + err.Error())
+		}
+
+		w := &watcher{gendir: gendir, cmd: cmd}
+		w.regenerate(true)
+		if watchDB {
+			go func() {
+				for {
+					if err := w.listen(); err != nil {
+						log.Printf("watch: %v; reconnecting in 5s", err)
+						time.Sleep(5 * time.Second)
+					}
+				}
+			}()
+		}
+		if err := w.watchFiles(); err != nil {
+			panic("error watching files: " + err.Error())
+		}
+	},
+}
+
+func init() {
+	watchCmd.Flags().BoolVar(&watchDB, "db", false, "also regenerate when DDL changes the database")
+	watchCmd.Flags().BoolVar(&watchInstallTrigger, "install-trigger", false, "install an event trigger notifying of DDL commands; needs superuser rights")
+	watchCmd.Flags().DurationVar(&watchInterval, "interval", 0, "also inspect the database this often, e.g. 30s, for when no trigger is installed")
+	rootCmd.AddCommand(watchCmd)
+}
+
+type watcher struct {
+	gendir string
+	cmd    *cobra.Command
+
+	// mu serializes regenerating, which file events and notifications can
+	// both ask for.
+	mu sync.Mutex
+	// last is the snapshot of the schemas last generated from, and stale is
+	// set while generating from it hasn't succeeded.
+	last  []byte
+	stale bool
+}
+
+// watchFiles regenerates after each burst of changes to the query file or
+// the templates. Their directories are watched rather than the files, as
+// editors often save by writing a new file and renaming it over the old one.
+func (w *watcher) watchFiles() error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+
+	queryFile, err := filepath.Abs(w.cmd.Flag("query").Value.String())
+	if err != nil {
+		return err
+	}
+	if err := fw.Add(filepath.Dir(queryFile)); err != nil {
+		return errors.WithMessage(err, filepath.Dir(queryFile))
+	}
+	watched := []string{queryFile}
+	var tplDir string
+	if templateDir != "" {
+		if tplDir, err = filepath.Abs(templateDir); err != nil {
+			return err
+		}
+		if err := fw.Add(tplDir); err != nil {
+			return errors.WithMessage(err, tplDir)
+		}
+		watched = append(watched, tplDir)
+	}
+	changed := func(ev fsnotify.Event) bool {
+		if ev.Op == fsnotify.Chmod {
+			return false
+		}
+		name := filepath.Clean(ev.Name)
+		return name == queryFile || tplDir != "" && filepath.Dir(name) == tplDir && strings.HasSuffix(name, ".tpl")
+	}
+	log.Printf("watching %s for changes", strings.Join(watched, " and "))
+
+	for {
+		select {
+		case ev := <-fw.Events:
+			if !changed(ev) {
+				continue
+			}
+		case err := <-fw.Errors:
+			return err
+		}
+		// Let the editor finish saving.
+		quiet := time.NewTimer(quietPeriod)
+	settle:
+		for {
+			select {
+			case ev := <-fw.Events:
+				if changed(ev) {
+					quiet.Reset(quietPeriod)
+				}
+			case err := <-fw.Errors:
+				return err
+			case <-quiet.C:
+				break settle
+			}
+		}
+		w.regenerate(false)
+	}
+}
+
+// listen waits for notifications on a connection of its own, and
+// regenerates after each burst of them. It returns when the connection
+// fails.
+func (w *watcher) listen() error {
+	conn, err := connect()
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if watchInstallTrigger {
+		if _, err := conn.Exec(installDDLTrigger); err != nil {
+			return errors.WithMessage(err, "installing event trigger")
+		}
+		// Installing it once is enough.
+		watchInstallTrigger = false
+	}
+	if err := conn.Listen(ddlChannel); err != nil {
+		return errors.WithMessage(err, "listening")
+	}
+	log.Printf("listening for DDL on channel %s", ddlChannel)
+
+	for {
+		n, err := w.wait(conn, watchInterval)
+		if err != nil {
+			return err
+		}
+		if n != nil {
+			// Let the rest of the migration run.
+			for n != nil {
+				if n, err = w.wait(conn, quietPeriod); err != nil {
+					return err
+				}
+			}
+		}
+		w.regenerate(true)
+	}
+}
+
+// wait waits up to timeout, or forever if it is 0, for a notification, and
+// returns nil when it times out.
+func (w *watcher) wait(conn *pgx.Conn, timeout time.Duration) (*pgx.Notification, error) {
+	ctx := context.Background()
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+	n, err := conn.WaitForNotification(ctx)
+	if err == context.DeadlineExceeded && conn.IsAlive() {
+		return nil, nil
+	}
+	return n, err
+}
+
+// regenerate generates the code. With reload, the schemas are read again
+// first, and nothing is generated if they didn't change since the last time;
+// otherwise the schemas last read are generated from again, as when only the
+// queries or templates changed. Failures are printed, so the watch goes on.
+func (w *watcher) regenerate(reload bool) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("watch: %v", r)
+		}
+	}()
+
+	var pgdata *pgxgen.PGData
+	if reload || w.last == nil {
+		var err error
+		if pgdata, err = loadSchema(); err != nil {
+			panic("error reading schema: " + err.Error())
+		}
+		var snap bytes.Buffer
+		if err := pgxgen.WriteSnapshot(&snap, pgdata, schemas...); err != nil {
+			panic("error writing snapshot: " + err.Error())
+		}
+		if bytes.Equal(snap.Bytes(), w.last) && !w.stale {
+			return
+		}
+		w.last = snap.Bytes()
+	} else {
+		// Generating changes the schemas it is given, so they are read
+		// back from the snapshot.
+		snap, err := pgxgen.ReadSnapshot(bytes.NewReader(w.last), schemas...)
+		if err != nil {
+			panic("error reading snapshot: " + err.Error())
+		}
+		pgdata = snap.PGData
+	}
+
+	start := time.Now()
+	w.stale = true
+	generateModels(w.gendir, w.cmd, pgdata)
+	w.stale = false
+	log.Printf("regenerated %s in %s", w.gendir, time.Since(start).Round(time.Millisecond))
+}
@@ -15,8 +15,11 @@
 package pgxgen
 
 import (
+	"sort"
 	"strings"
 
+	"github.com/pkg/errors"
+	"github.com/tangzero/inflector"
 )
 
 type QueryDefinitions struct {
@@ -38,7 +41,11 @@ type Query struct {
 	Sort       []Sort
 	ReturnOne  bool
 	ReturnMany bool
+	ReturnList bool
 	Paged      bool
+	// Where is an additional SQL condition rows must satisfy, such as the
+	// predicate of a partial unique index.
+	Where string
 }
 
 func (q *Query) ExportedName() string {
@@ -80,11 +87,215 @@ func (s Sort) String() string {
 	return "ASC"
 }
 
+// Relation is a foreign key resolved against the inspected tables. Lookup is
+// the name of the query that loads the parent row, filtered on the parent's
+// referenced columns and fed from Columns of the referencing row.
+type Relation struct {
+	Name       string
+	Table      Table
+	Parent     Table
+	ForeignKey ForeignKey
+	Columns    []Column
+	Lookup     string
+}
+
+func (r *Relation) ExportedName() string {
+	return r.Table.ExportedName() + r.Name
+}
+
+func (r *Relation) resolve(data PGData) bool {
+	p, ok := data.Tables[r.ForeignKey.RefSchema+"."+r.ForeignKey.RefTable]
+	if !ok {
+		return false
+	}
+	r.Parent = *p
+	for k, n := range r.ForeignKey.RefColumns {
+		c := p.Column(n)
+		if c == nil || c.typeKey() != r.ForeignKey.Columns[k].typeKey() {
+			return false
+		}
+	}
+	isPK := len(p.PrimaryKeys) == len(r.ForeignKey.RefColumns)
+	for k, pk := range p.PrimaryKeys {
+		isPK = isPK && pk.Name == r.ForeignKey.RefColumns[k]
+	}
+	r.Lookup = "Get" + p.ExportedName()
+	if !isPK {
+		r.Lookup += "By" + joinExported(r.ForeignKey.RefColumns)
+	}
+	return true
+}
+
+// lookupQuery returns the query loading the parent row of the relation.
+func (r *Relation) lookupQuery() Query {
+	q := Query{Name: r.Lookup, Table: r.Parent, Filter: []Filter{}, Sort: []Sort{}, ReturnOne: true}
+	for _, n := range r.ForeignKey.RefColumns {
+		q.Filter = append(q.Filter, Filter{Column: *r.Parent.Column(n), Op: "eq"})
+	}
+	return q
+}
+
+// listQuery returns the query loading all rows of the referencing table
+// which point at a given parent.
+func (r *Relation) listQuery() Query {
+	q := Query{Name: r.ListName(), Table: r.Table, Filter: []Filter{}, Sort: []Sort{}, ReturnList: true}
+	for _, c := range r.Columns {
+		q.Filter = append(q.Filter, Filter{Column: c, Op: "eq"})
+	}
+	for _, pk := range r.Table.PrimaryKeys {
+		q.Sort = append(q.Sort, Sort{Column: *pk})
+	}
+	return q
+}
+
+// ListName is the name of the list query, e.g. ListOrdersByAccountID. Table
+// names are singularized first, as they are often plural already.
+func (r *Relation) ListName() string {
+	var cols []string
+	for _, c := range r.Columns {
+		cols = append(cols, c.Name)
+	}
+	return "List" + inflector.Pluralize(inflector.Singularize(r.Table.ExportedName())) + "By" + joinExported(cols)
+}
+
+func joinExported(ss []string) string {
+	var r string
+	for _, s := range ss {
+		r += ExportedName(s)
+	}
+	return r
+}
+
+// ProcessRelations resolves the foreign keys of all tables, in the order of
+// the tables' qualified names. Foreign keys to tables that were not
+// inspected, or whose column types differ from the referenced columns, are
+// skipped.
+func ProcessRelations(data PGData) []Relation {
+	var rr []Relation
+	for _, t := range data.SortedTables() {
+		for _, fk := range t.ForeignKeys {
+			r := Relation{Name: fk.ExportedName(), Table: *t, ForeignKey: *fk}
+			for _, c := range fk.Columns {
+				r.Columns = append(r.Columns, *c)
+			}
+			if !r.resolve(data) {
+				continue
+			}
+			rr = append(rr, r)
+		}
+	}
+	return rr
+}
+
+// filterOps are the ops a field of a query definition can be compared with.
+var filterOps = map[string]bool{"eq": true, "ne": true, "lt": true, "lteq": true, "gt": true, "gteq": true, "in": true}
+
+// ValidateQueryDefinitions lists the mistakes in def which
+// ProcessQueryDefinitions would pass over: unnamed or repeated queries,
+// tables or columns that aren't in data, unknown ops and return values. The
+// unique indexes of data no lookup is generated for are listed too.
+func ValidateQueryDefinitions(def QueryDefinitions, data PGData) []error {
+	var errs []error
+	seen := map[string]bool{}
+	for k, d := range def.Query {
+		if d.Name == "" {
+			errs = append(errs, errors.Errorf("query %d: no name", k+1))
+			continue
+		}
+		if seen[d.Name] {
+			errs = append(errs, errors.Errorf("query %s: defined more than once", d.Name))
+		}
+		seen[d.Name] = true
+
+		switch d.Return {
+		case "", "one", "many", "paged":
+		default:
+			errs = append(errs, errors.Errorf("query %s: unknown return %q, want one, many or paged", d.Name, d.Return))
+		}
+		t := data.Table(d.Table)
+		if t == nil {
+			errs = append(errs, errors.Errorf("query %s: no table %s, or it is excluded", d.Name, d.Table))
+			continue
+		}
+		for _, f := range d.Fields {
+			ff := strings.Split(f, ":")
+			if len(ff) > 2 {
+				errs = append(errs, errors.Errorf("query %s: field %q isn't column or column:op", d.Name, f))
+				continue
+			}
+			if t.Column(ff[0]) == nil {
+				errs = append(errs, errors.Errorf("query %s: no column %s in %s", d.Name, ff[0], t.QualifiedName()))
+			}
+			if len(ff) == 2 && !filterOps[ff[1]] {
+				errs = append(errs, errors.Errorf("query %s: unknown op %q of %s", d.Name, ff[1], ff[0]))
+			}
+		}
+		for _, f := range d.Sort {
+			if c := strings.TrimLeft(f, "+-"); t.Column(c) == nil {
+				errs = append(errs, errors.Errorf("query %s: no column %s to sort by in %s", d.Name, c, t.QualifiedName()))
+			}
+		}
+	}
+
+	taken := map[string]bool{}
+	for _, d := range def.Query {
+		if data.Table(d.Table) != nil {
+			taken[d.Name] = true
+		}
+	}
+	_, skipped := uniqueLookups(data, taken)
+	return append(errs, skipped...)
+}
+
+// uniqueLookups returns the lookups of the unique indexes of data, named
+// Get<Table>By<Columns>, leaving out those whose name is in seen, which is
+// updated. Lookups on expressions can't be keyed by column values and are
+// left out. Of unique indexes on the same columns, the lookup is generated
+// for one without a predicate, or else the first by name; the indexes left
+// out are returned as errors.
+func uniqueLookups(data PGData, seen map[string]bool) ([]Query, []error) {
+	var qq []Query
+	var skipped []error
+	for _, t := range data.SortedTables() {
+		var indexes []*Index
+		for _, ix := range t.Indexes {
+			if ix.IsUnique && !ix.IsPrimary && !ix.HasExpressions() {
+				indexes = append(indexes, ix)
+			}
+		}
+		sort.SliceStable(indexes, func(i, j int) bool { return !indexes[i].IsPartial() && indexes[j].IsPartial() })
+		for _, ix := range indexes {
+			q := Query{Table: *t, Filter: []Filter{}, Sort: []Sort{}, ReturnOne: true, Where: ix.Predicate}
+			var cols []string
+			for _, c := range ix.Columns {
+				cols = append(cols, c.Name)
+				q.Filter = append(q.Filter, Filter{Column: *c, Op: "eq"})
+			}
+			q.Name = "Get" + t.ExportedName() + "By" + joinExported(cols)
+			if seen[q.Name] {
+				skipped = append(skipped, errors.Errorf("index %s.%s: no lookup generated, as %s is already taken", t.QualifiedName(), ix.Name, q.Name))
+				continue
+			}
+			seen[q.Name] = true
+			qq = append(qq, q)
+		}
+	}
+	return qq, skipped
+}
+
+// ProcessQueryDefinitions builds the queries of def along with the lookups
+// derived from the tables in data. Tables of a definition are named either
+// bare or schema qualified; definitions for tables outside of data, such as
+// those of another schema, are skipped.
 func ProcessQueryDefinitions(def QueryDefinitions, data PGData) []Query {
 	var qq []Query
 	for _, d := range def.Query {
+		t := data.Table(d.Table)
+		if t == nil {
+			continue
+		}
 		q := Query{Name: d.Name}
-		q.Table = *data.Tables[d.Table]
+		q.Table = *t
 		q.Filter = []Filter{}
 		couldReturnMany := false
 		for _, f := range d.Fields {
@@ -117,7 +328,10 @@ func ProcessQueryDefinitions(def QueryDefinitions, data PGData) []Query {
 	}
 
 	// Add primary key for tables
-	for _, t := range data.Tables {
+	for _, t := range data.SortedTables() {
+		if len(t.PrimaryKeys) == 0 {
+			continue
+		}
 		q := Query{Name: "Get" + t.ExportedName()}
 		q.Table = *t
 		q.Filter = []Filter{}
@@ -133,5 +347,24 @@ func ProcessQueryDefinitions(def QueryDefinitions, data PGData) []Query {
 		q.Paged = false
 		qq = append(qq, q)
 	}
+
+	seen := map[string]bool{}
+	for _, q := range qq {
+		seen[q.Name] = true
+	}
+
+	lookups, _ := uniqueLookups(data, seen)
+	qq = append(qq, lookups...)
+
+	// Add parent lookups and child lists for foreign keys
+	for _, r := range ProcessRelations(data) {
+		for _, q := range []Query{r.lookupQuery(), r.listQuery()} {
+			if seen[q.Name] {
+				continue
+			}
+			seen[q.Name] = true
+			qq = append(qq, q)
+		}
+	}
 	return qq
 }
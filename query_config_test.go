@@ -0,0 +1,82 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRelationListName(t *testing.T) {
+	tests := []struct {
+		table   string
+		columns []string
+		want    string
+	}{
+		{"orders", []string{"account_id"}, "ListOrdersByAccountID"},
+		{"leases", []string{"host_ip"}, "ListLeasesByHostIP"},
+		{"order_item", []string{"order_id"}, "ListOrderItemsByOrderID"},
+		{"order_items", []string{"order_id", "line"}, "ListOrderItemsByOrderIDLine"},
+	}
+	for _, tt := range tests {
+		r := Relation{Table: Table{Schema: "public", Name: tt.table}}
+		for _, c := range tt.columns {
+			r.Columns = append(r.Columns, Column{Name: c})
+		}
+		if got := r.ListName(); got != tt.want {
+			t.Errorf("ListName of %s by %v = %s, want %s", tt.table, tt.columns, got, tt.want)
+		}
+	}
+}
+
+func TestUniqueLookups(t *testing.T) {
+	data, err := ParseDDL(`
+CREATE TABLE public.users (id int PRIMARY KEY, email text, login text, deleted_at timestamptz);
+CREATE UNIQUE INDEX users_a_email_live ON public.users (email) WHERE (deleted_at IS NULL);
+CREATE UNIQUE INDEX users_b_email ON public.users (email);
+CREATE UNIQUE INDEX users_login ON public.users (login);
+`)
+	if err != nil {
+		t.Fatalf("ParseDDL: %v", err)
+	}
+	def := QueryDefinitions{Query: []QueryDefinition{
+		{Name: "GetUsersByLogin", Table: "users", Fields: []string{"login"}, Return: "one"},
+	}}
+
+	var lookups []string
+	for _, q := range ProcessQueryDefinitions(def, *data) {
+		if q.Name == "GetUsersByEmail" {
+			lookups = append(lookups, q.Name)
+			if q.Where != "" {
+				t.Errorf("GetUsersByEmail is of the partial index, where %s", q.Where)
+			}
+		}
+	}
+	if len(lookups) != 1 {
+		t.Errorf("GetUsersByEmail generated %d times, want once", len(lookups))
+	}
+
+	var errs []string
+	for _, err := range ValidateQueryDefinitions(def, *data) {
+		errs = append(errs, err.Error())
+	}
+	want := []string{
+		"index public.users.users_login: no lookup generated, as GetUsersByLogin is already taken",
+		"index public.users.users_a_email_live: no lookup generated, as GetUsersByEmail is already taken",
+	}
+	if !reflect.DeepEqual(errs, want) {
+		t.Errorf("ValidateQueryDefinitions =\n\t%q\nwant\n\t%q", errs, want)
+	}
+}
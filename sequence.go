@@ -0,0 +1,83 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+// Sequence is a sequence, either standalone or owned by a column, such as
+// those created for serial and identity columns, and dropped along with it.
+type Sequence struct {
+	Schema string `json:"schema"`
+	Name   string `json:"name"`
+	Prefix string `json:"-"`
+	// DataType is int2, int4 or int8.
+	DataType string `json:"dataType"`
+	// OwnerTable is the qualified name of the table of the column owning
+	// the sequence, and OwnerColumn its name.
+	OwnerTable  string `json:"ownerTable,omitempty"`
+	OwnerColumn string `json:"ownerColumn,omitempty"`
+	// Comment is set with COMMENT ON SEQUENCE.
+	Comment string `json:"comment,omitempty"`
+}
+
+func (s *Sequence) QualifiedName() string {
+	return s.Schema + "." + s.Name
+}
+
+func (s *Sequence) ExportedName() string {
+	return ExportedName(s.Prefix + s.Name)
+}
+
+// GoType is the Go type of the values of the sequence.
+func (s *Sequence) GoType() string {
+	switch s.DataType {
+	case "int2":
+		return "int16"
+	case "int4":
+		return "int32"
+	}
+	return "int64"
+}
+
+// CommentLines returns the comment of the sequence split in lines, for use
+// in Go comments.
+func (s *Sequence) CommentLines() []string {
+	return commentLines(s.Comment)
+}
+
+// IsOwned reports whether the sequence belongs to a column.
+func (s *Sequence) IsOwned() bool {
+	return s.OwnerTable != ""
+}
+
+// ownerName returns the qualified name of the column owning s, or "".
+func (s *Sequence) ownerName() string {
+	if !s.IsOwned() {
+		return ""
+	}
+	return s.OwnerTable + "." + s.OwnerColumn
+}
+
+// owner returns the column owning s in data, or nil.
+func (s *Sequence) owner(data *PGData) *Column {
+	if t := data.Tables[s.OwnerTable]; t != nil {
+		return t.Column(s.OwnerColumn)
+	}
+	return nil
+}
+
+// Regclass is the SQL expression of the sequence's OID, for passing to
+// nextval and currval.
+func (s *Sequence) Regclass() string {
+	return quoteLiteral(quoteName(s.Schema, s.Name)) + "::REGCLASS"
+}
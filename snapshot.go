@@ -0,0 +1,223 @@
+// Copyright © 2018 Sharon Lourduraj
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pgxgen
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// SnapshotVersion is the version of the snapshot format WriteSnapshot
+// writes. It is bumped whenever the format changes in a way older versions
+// can't read.
+const SnapshotVersion = 1
+
+// Snapshot is the inspected schemas, as written to a schema snapshot file.
+type Snapshot struct {
+	Version int      `json:"version"`
+	Schemas []string `json:"schemas"`
+	*PGData
+}
+
+// WriteSnapshot writes data, inspected from schemas, as indented JSON. Maps
+// are written with sorted keys so the same schema always gives the same
+// snapshot, and changes show up as small diffs.
+func WriteSnapshot(w io.Writer, data *PGData, schemas ...string) error {
+	b, err := json.MarshalIndent(&Snapshot{Version: SnapshotVersion, Schemas: schemas, PGData: data}, "", "  ")
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	_, err = w.Write(append(b, '\n'))
+	return errors.WithStack(err)
+}
+
+// ReadSnapshot reads a snapshot written by WriteSnapshot, keeping the
+// objects in schemas, or in all its schemas when none are given, as Inspect
+// would have returned them.
+func ReadSnapshot(r io.Reader, schemas ...string) (*Snapshot, error) {
+	var s Snapshot
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return nil, errors.WithMessage(err, "reading snapshot")
+	}
+	switch {
+	case s.Version == 0 || s.PGData == nil:
+		return nil, errors.New("not a schema snapshot")
+	case s.Version > SnapshotVersion:
+		return nil, errors.Errorf("snapshot version %d is newer than this pgxgen, which reads up to version %d", s.Version, SnapshotVersion)
+	}
+
+	if len(schemas) == 0 {
+		schemas = s.Schemas
+	}
+	in := map[string]bool{}
+	for _, sch := range schemas {
+		found := false
+		for _, ss := range s.Schemas {
+			found = found || ss == sch
+		}
+		if !found {
+			return nil, errors.Errorf("schema %s is not in the snapshot, which has %v", sch, s.Schemas)
+		}
+		in[sch] = true
+	}
+
+	data := &PGData{
+		Enums:      map[string]*Enum{},
+		Composites: map[string]*Composite{},
+		Domains:    map[string]*Domain{},
+		Tables:     map[string]*Table{},
+	}
+	for k, en := range s.Enums {
+		if in[en.Schema] {
+			data.Enums[k] = en
+			registerEnum(en)
+		}
+	}
+	for k, d := range s.Domains {
+		if in[d.Schema] {
+			data.Domains[k] = d
+		}
+	}
+	for k, c := range s.Composites {
+		if in[c.Schema] {
+			data.Composites[k] = c
+			registerComposite(c)
+		}
+	}
+	for k, t := range s.Tables {
+		if !in[t.Schema] {
+			continue
+		}
+		if err := t.relink(); err != nil {
+			return nil, errors.WithMessage(err, "table "+k)
+		}
+		data.Tables[k] = t
+	}
+	for k, f := range s.Functions {
+		if !in[f.Schema] {
+			continue
+		}
+		if data.Functions == nil {
+			data.Functions = map[string]*Function{}
+		}
+		data.Functions[k] = f
+	}
+	for k, sq := range s.Sequences {
+		if !in[sq.Schema] {
+			continue
+		}
+		if data.Sequences == nil {
+			data.Sequences = map[string]*Sequence{}
+		}
+		data.Sequences[k] = sq
+	}
+	return &Snapshot{Version: s.Version, Schemas: schemas, PGData: data}, nil
+}
+
+// relink points the columns of indexes and foreign keys, read from a
+// snapshot by name, back at the columns of t.
+func (t *Table) relink() error {
+	for _, ix := range t.Indexes {
+		for k, c := range ix.Columns {
+			if c != nil {
+				ix.Columns[k] = t.Column(c.Name)
+				if ix.Columns[k] == nil {
+					return errors.Errorf("index %s: unknown column %s", ix.Name, c.Name)
+				}
+			}
+		}
+	}
+	for _, fk := range t.ForeignKeys {
+		for k, c := range fk.Columns {
+			fk.Columns[k] = t.Column(c.Name)
+			if fk.Columns[k] == nil {
+				return errors.Errorf("foreign key %s: unknown column %s", fk.Name, c.Name)
+			}
+		}
+	}
+	sort.Slice(t.Columns, func(i, j int) bool { return t.Columns[i].Position < t.Columns[j].Position })
+	setPrimaryKeys(t)
+	return nil
+}
+
+// columnNames returns the names of cc, with null for nil columns.
+func columnNames(cc []*Column) []*string {
+	nn := make([]*string, len(cc))
+	for k, c := range cc {
+		if c != nil {
+			nn[k] = &c.Name
+		}
+	}
+	return nn
+}
+
+// namedColumns returns placeholder columns for nn, to be relinked once the
+// table is read.
+func namedColumns(nn []*string) []*Column {
+	cc := make([]*Column, len(nn))
+	for k, n := range nn {
+		if n != nil {
+			cc[k] = &Column{Name: *n}
+		}
+	}
+	return cc
+}
+
+// Indexes and foreign keys refer to their columns by name in snapshots.
+
+func (i *Index) MarshalJSON() ([]byte, error) {
+	type index Index
+	return json.Marshal(struct {
+		*index
+		Columns []*string `json:"columns"`
+	}{(*index)(i), columnNames(i.Columns)})
+}
+
+func (i *Index) UnmarshalJSON(b []byte) error {
+	type index Index
+	v := struct {
+		*index
+		Columns []*string `json:"columns"`
+	}{index: (*index)(i)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	i.Columns = namedColumns(v.Columns)
+	return nil
+}
+
+func (fk *ForeignKey) MarshalJSON() ([]byte, error) {
+	type foreignKey ForeignKey
+	return json.Marshal(struct {
+		*foreignKey
+		Columns []*string `json:"columns"`
+	}{(*foreignKey)(fk), columnNames(fk.Columns)})
+}
+
+func (fk *ForeignKey) UnmarshalJSON(b []byte) error {
+	type foreignKey ForeignKey
+	v := struct {
+		*foreignKey
+		Columns []*string `json:"columns"`
+	}{foreignKey: (*foreignKey)(fk)}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	fk.Columns = namedColumns(v.Columns)
+	return nil
+}
@@ -30,6 +30,7 @@ const (
     ErrCodeUnknown          ErrCode = iota
 	ErrCodeNotFound
 	ErrCodeDuplicate
+	ErrCodeCheckViolation
 )
 
 type Error struct {
@@ -63,4 +64,12 @@ func IsErrDuplicate(err error) bool {
 	return e.Code == ErrCodeDuplicate
 }
 
+func IsErrCheckViolation(err error) bool {
+	e, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	return e.Code == ErrCodeCheckViolation
+}
+
 // ---------------------------------------------------------------------------------------------------------------------
\ No newline at end of file
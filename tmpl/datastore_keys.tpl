@@ -26,7 +26,7 @@ func (k Key{{.Name}}) String() string { return MkKeyStr{{.Name}}({{range $k, $fd
 
 func (k Key{{.Name}}) Raw() interface{} { return k }
 
-{{if .ReturnOne}}
+{{if or .ReturnOne .ReturnList}}
 func MkKeyStr{{.Name}}({{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.GoVar}} {{if eq .Op "in"}}[]{{end}}{{.Column.QualifiedPgxType  $.ModelPackageName}}{{end}}) string {
     k := "keyFor{{.Name}}"
     {{range $k, $fd := .Filter -}}
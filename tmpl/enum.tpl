@@ -18,7 +18,7 @@ type {{.Enum.GoType}} pgtype.Text
 
 var (
 {{range .Enum.Values}}
-    {{exported $.Enum.Name .Value }} =  {{$.Enum.GoType}}(pgtype.Text{String: "{{.Value}}", Status: pgtype.Present}) // const for {{$.Enum.Name}}'s {{.Value}}
+    {{$.Enum.ExportedName}}{{.ExportedName}} =  {{$.Enum.GoType}}(pgtype.Text{String: "{{.Value}}", Status: pgtype.Present}) // const for {{$.Enum.Name}}'s {{.Value}}
 {{- end}}
 )
 
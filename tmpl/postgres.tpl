@@ -17,6 +17,12 @@ func ToDatastoreErr(fn string, err error) error {
     if pge, ok := err.(pgx.PgError); ok && pge.Code == "23505" {
         return &datastore.Error{Err: err, Code: datastore.ErrCodeDuplicate, Impl: "{{.PackageName}}", Function: fn}
     }
+    if pge, ok := err.(pgx.PgError); ok && pge.Code == "23514" {
+        return &datastore.Error{Err: err, Code: datastore.ErrCodeCheckViolation, Impl: "{{.PackageName}}", Function: fn}
+    }
+    if _, ok := err.(interface{ CheckConstraint() string }); ok {
+        return &datastore.Error{Err: err, Code: datastore.ErrCodeCheckViolation, Impl: "{{.PackageName}}", Function: fn}
+    }
     return &datastore.Error{Err: err, Code: datastore.ErrCodeUnknown, Impl: "{{.PackageName}}", Function: fn}
 }
 
@@ -14,12 +14,12 @@ import (
 )
 
 {{range .Queries}}
-func (st *PGDatastore) {{.Name}}({{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.GoVar}} {{if eq .Op "in"}}[]{{end}}{{.Column.QualifiedPgxType  $.ModelPackageName}}{{end}}) (*{{$.ModelPackageName}}.{{.Table.ExportedName}}, error) {
+func (st *PGDatastore) {{.Name}}({{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.GoVar}} {{if eq .Op "in"}}[]{{end}}{{.Column.QualifiedPgxType  $.ModelPackageName}}{{end}}) ({{if .ReturnList}}[]{{end}}*{{$.ModelPackageName}}.{{.Table.ExportedName}}, error) {
      d, err := st.generatedLoaders.{{.Name}}.Load(context.Background(), datastore.Key{{.Name}}{ {{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.ExportedName}}: {{.Column.GoVar}}{{end}} })()
      if err != nil {
         return nil, err
      }
-     return d.(*{{$.ModelPackageName}}.{{.Table.ExportedName}}), nil
+     return d.({{if .ReturnList}}[]{{end}}*{{$.ModelPackageName}}.{{.Table.ExportedName}}), nil
 }
 
 {{if .ReturnOne}}
@@ -51,7 +51,7 @@ func batchFunc{{.Name}}(conn datastore.PostgresConnection) dataloader.BatchFunc
                 pars = append(pars, "(" + strings.Join(p, ", ") + ")")
             }
 
-            q := "SELECT " + {{.Table.ExportedName}}FieldsStr + " FROM {{.Table.Schema}}.{{.Table.Name}} WHERE ({{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.Name}}{{end}}) IN (" + strings.Join(pars, ",") + ");"
+            q := "SELECT " + {{.Table.ExportedName}}FieldsStr + " FROM {{.Table.Schema}}.{{.Table.Name}} WHERE ({{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.Name}}{{end}}) IN (" + strings.Join(pars, ",") + "){{with .Where}} AND (" + {{printf "%q" .}} + "){{end}};"
 
             rows, err := conn.Query(q, args...)
             defer rows.Close()
@@ -76,6 +76,47 @@ func batchFunc{{.Name}}(conn datastore.PostgresConnection) dataloader.BatchFunc
 }
 {{end}}
 
+{{if .ReturnList}}
+func batchFunc{{.Name}}(conn datastore.PostgresConnection) dataloader.BatchFunc {
+    return func(_ context.Context, keys dataloader.Keys) []*dataloader.Result {
+            var results []*dataloader.Result
+            var args []interface{}
+            var pars []string
+            var i int
+            rmap := make(map[string][]*{{$.ModelPackageName}}.{{.Table.ExportedName}})
+            for _, k := range keys {
+                key, ok := k.(datastore.Key{{.Name}})
+                if !ok {
+                    continue
+                }
+                var p []string
+                {{range .Filter -}}
+                {
+                    i++
+                    args = append(args, &key.{{.Column.ExportedName}})
+                    p = append(p, "$" + strconv.Itoa(i))
+                }
+                {{end}}
+                pars = append(pars, "(" + strings.Join(p, ", ") + ")")
+            }
+            q := "SELECT " + {{.Table.ExportedName}}FieldsStr + " FROM {{.Table.Schema}}.{{.Table.Name}} WHERE ({{range $k, $fd := .Filter}}{{if $k}}, {{end}}{{.Column.Name}}{{end}}) IN (" + strings.Join(pars, ",") + "){{if .Sort}} ORDER BY {{range $k, $s := .Sort}}{{if $k}}, {{end}}{{.Column.Name}} {{.}}{{end}}{{end}};"
+            rows, err := conn.Query(q, args...)
+            defer rows.Close()
+            rr, err := Scan{{pluralize .Table.ExportedName}}(rows)
+            if err != nil {
+                // Log error
+            }
+            for _, r := range rr {
+                key := datastore.MkKeyStr{{.Name}}({{range $k, $fd := .Filter}}{{if $k}}, {{end}}r.{{.Column.ExportedName}}{{end}})
+                rmap[key] = append(rmap[key], r)
+            }
+            for _, key := range keys {
+                results = append(results, &dataloader.Result{Data: rmap[key.String()], Error: ToDatastoreErr("{{.Name}}BatchFunc", err)})
+            }
+            return results
+        }
+}
+{{end}}
 {{if .ReturnMany}}
 func batchFunc{{.Name}}(conn datastore.PostgresConnection) dataloader.BatchFunc {
     return func(_ context.Context, keys dataloader.Keys) []*dataloader.Result {
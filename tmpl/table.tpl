@@ -7,14 +7,43 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
     pgtype "github.com/jackc/pgx/pgtype"
     uuid "github.com/satori/go.uuid"
 )
 
-// {{.Table.ExportedName}} represents row data from the table '{{.Table.Name}}.'
+// {{.Table.ExportedName}} represents row data from the {{.Table.Kind}} '{{.Table.Name}}.'
+{{- with .Table.CommentLines}}
+//
+{{- range .}}
+// {{.}}
+{{- end}}
+{{- end}}
 type {{.Table.ExportedName}} struct {
 {{range .Table.Columns -}}
-    {{.ExportedName}} {{.PgxType}} // column: '{{.Name}}'
+    {{range .CommentLines}}// {{.}}
+    {{end}}{{.ExportedName}} {{.PgxType}} // column: '{{.Name}}'{{with .Modifier}}, numeric{{.}}{{end}}{{with .ServerDefault}}, {{.}}{{end}}
 {{end -}}
-}
\ No newline at end of file
+}
+
+// Validate checks m against the CHECK constraints of '{{.Table.Name}}' which translate to Go, and returns a
+// *CheckError for the first one violated. A constraint over a field left Undefined passes, as does one over a NULL
+// field unless it tests for NULL, like in the database.
+{{- with .Table.UntranslatedChecks}}
+// The following constraints are only enforced by the database:
+{{- range .}}
+{{- $name := .Name}}
+{{- range $k, $line := .DefinitionLines}}
+//      {{if $k}}    {{else}}{{$name}}: {{end}}{{$line}}
+{{- end}}
+{{- end}}
+{{- end}}
+func (m *{{.Table.ExportedName}}) Validate() error {
+{{- range .Table.Validations "m"}}
+    if {{.Cond}} {
+        return &CheckError{Table: "{{$.Table.Name}}", Constraint: "{{.Check.Name}}", Definition: {{printf "%q" .Check.Definition}}}
+    }
+{{- end}}
+    return nil
+}
@@ -2,6 +2,7 @@
 package {{.PackageName}}
 
 import (
+	"context"
 	"strconv"
 	"strings"
 
@@ -14,9 +15,11 @@ import (
 
 //
 const (
-    {{.Table.ExportedName}}Table = "{{.Table.Name}}"
+    {{range .Table.CommentLines}}// {{.}}
+    {{end}}{{.Table.ExportedName}}Table = "{{.Table.Name}}"
 {{range .Table.Columns -}}
-    {{$.Table.ExportedName}}Field{{.ExportedName}} = "{{.Name}}"
+    {{range .CommentLines}}// {{.}}
+    {{end}}{{$.Table.ExportedName}}Field{{.ExportedName}} = "{{.Name}}"
 {{end -}}
 )
 
@@ -67,31 +70,55 @@ func Scan{{pluralize .Table.ExportedName}}(rows *pgx.Rows) ([]*{{.ModelPackageNa
 	return r, nil
 }
 
-// Create{{.Table.ExportedName}} create a single row in '{{.Table.Name}}' and return it.
+{{if not .Table.IsReadOnly}}
+// Create{{.Table.ExportedName}} create a single row in '{{.Table.Name}}' and return it. The row is validated against
+// the table's CHECK constraints before it is sent to the database. Fields left Undefined are
+// omitted from the insert, and filled in by the server where the column has a default:
+{{- range .Table.Columns}}{{if .ServerDefault}}
+//      {{.Name}}: {{.ServerDefault}}{{if .IsReadOnly}} (read-only, always omitted){{end}}
+{{- end}}{{end}}
 func Create{{.Table.ExportedName}}(conn datastore.PostgresConnection, m *{{.ModelPackageName}}.{{.Table.ExportedName}}) (*{{.ModelPackageName}}.{{.Table.ExportedName}}, error) {
+	if err := m.Validate(); err != nil {
+		return nil, ToDatastoreErr("Create{{.Table.ExportedName}}", err)
+	}
+
 	var f []string
 	var v []string
 	var c int
 	var a []interface{}
 
     {{range .Table.Columns}}
+        {{- if not .IsReadOnly}}
         if m.{{.ExportedName}}.Status != pgtype.Undefined {
             c++
             f = append(f, "{{.Name}}")
             v = append(v, "$"+strconv.Itoa(c))
             a = append(a, &m.{{.ExportedName}})
         }
+        {{- end}}
     {{- end}}
 
 	q := "INSERT INTO {{.Table.Schema}}.{{.Table.Name}} (" + strings.Join(f, ", ") + ") VALUES(" + strings.Join(v, ", ") + ") RETURNING " + {{.Table.ExportedName}}FieldsStr + ";"
+	if len(f) == 0 {
+		q = "INSERT INTO {{.Table.Schema}}.{{.Table.Name}} DEFAULT VALUES RETURNING " + {{.Table.ExportedName}}FieldsStr + ";"
+	}
 
 	row := conn.QueryRow(q, a...)
 	r, err := Scan{{.Table.ExportedName}}(row)
 	return r, ToDatastoreErr("Create{{.Table.ExportedName}}", err)
 }
 
-// Update{{.Table.ExportedName}} updates a row in '{{.Table.Name}}.'
+{{if .Table.PrimaryKeys}}
+// Update{{.Table.ExportedName}} updates a row in '{{.Table.Name}}.' Fields left Undefined are left unchanged, and are
+// not validated against the table's CHECK constraints.
+{{- range .Table.Columns}}{{if .IsReadOnly}}
+// Field {{.ExportedName}} is read-only and never written.
+{{- end}}{{end}}
 func Update{{.Table.ExportedName}}(conn datastore.PostgresConnection, {{range $k, $pk := .Table.PrimaryKeys}}{{if $k}}, {{end}}{{.GoVar}} {{.QualifiedGoType $.ModelPackageName}}{{end}}, m *{{.ModelPackageName}}.{{.Table.ExportedName}}) (*{{.ModelPackageName}}.{{.Table.ExportedName}}, error) {
+	if err := m.Validate(); err != nil {
+		return nil, ToDatastoreErr("Update{{.Table.ExportedName}}", err)
+	}
+
 	var f []string
 	var pk []string
 	var c int
@@ -106,7 +133,7 @@ func Update{{.Table.ExportedName}}(conn datastore.PostgresConnection, {{range $k
     {{- end}}
 
     {{range .Table.Columns}}
-        {{- if not .IsPK}}
+        {{- if not (or .IsPK .IsReadOnly)}}
         if m.{{.ExportedName}}.Status != pgtype.Undefined {
             c++
             f = append(f, "{{.Name}} = $"+strconv.Itoa(c))
@@ -128,3 +155,18 @@ func Delete{{.Table.ExportedName}}(conn datastore.PostgresConnection, {{range $k
 	_, err := conn.Exec(q, {{range $k, $pk := .Table.PrimaryKeys}}{{if $k}}, {{end}}{{.GoVarTemplate}}{{end}})
     return ToDatastoreErr("Delete{{.Table.ExportedName}}", err)
 }
+{{end}}
+{{end}}
+
+{{if .Table.IsMaterializedView}}
+// Refresh{{.Table.ExportedName}} refreshes the materialized view '{{.Table.Name}}.' A concurrent refresh doesn't lock out
+// readers, but requires a unique index on the view.
+func (st *PGDatastore) Refresh{{.Table.ExportedName}}(ctx context.Context, concurrently bool) error {
+	q := "REFRESH MATERIALIZED VIEW {{.Table.Schema}}.{{.Table.Name}};"
+	if concurrently {
+		q = "REFRESH MATERIALIZED VIEW CONCURRENTLY {{.Table.Schema}}.{{.Table.Name}};"
+	}
+	_, err := st.conn.ExecEx(ctx, q, nil)
+	return ToDatastoreErr("Refresh{{.Table.ExportedName}}", err)
+}
+{{end}}
@@ -17,7 +17,8 @@ import (
 type tbl{{.Table.ExportedName}} struct {
     *table
 {{range .Table.Columns -}}
-    {{.ExportedName}} *column   // column: '{{.Name}}'
+    {{range .CommentLines}}// {{.}}
+    {{end}}{{.ExportedName}} *column   // column: '{{.Name}}'
 {{end -}}
 }
 
@@ -34,6 +35,12 @@ func ({{.Table.ShortName}} *tbl{{.Table.ExportedName}}) GetColumns() []*column {
 }
 
 // {{$.Table.ExportedName}} exposes table '{{.Table.Name}}' to the query builder.
+{{- with .Table.CommentLines}}
+//
+{{- range .}}
+// {{.}}
+{{- end}}
+{{- end}}
 var {{$.Table.ExportedName}} = &tbl{{.Table.ExportedName}}{
      table: &table{
         name:   "{{.Table.Name}}",
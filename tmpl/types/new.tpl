@@ -2,6 +2,9 @@
 package {{.PackageName}}
 
 import (
+	"math/big"
+	"net"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/pgtype"
@@ -18,6 +21,20 @@ func NewJSONB(b []byte) pgtype.JSONB     { return pgtype.JSONB{Bytes: b, Status:
 func Now() pgtype.Timestamp                      { return pgtype.Timestamp{Time: time.Now(), Status: pgtype.Present} }
 func NewTimestamp(t time.Time) pgtype.Timestamp     { return pgtype.Timestamp{Time: t, Status: pgtype.Present} }
 func NewTimestamptz(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Status: pgtype.Present} }
+func NewDate(t time.Time) pgtype.Date               { return pgtype.Date{Time: t, Status: pgtype.Present} }
+func NewInterval(d time.Duration) pgtype.Interval {
+	return pgtype.Interval{Microseconds: int64(d / time.Microsecond), Status: pgtype.Present}
+}
+{{- if .Data.Uses "time" "timetz"}}
+
+// NewTime returns the time of day d after midnight.
+func NewTime(d time.Duration) {{$.ModelPackageName}}.Time {
+	return {{$.ModelPackageName}}.Time{Microseconds: int64(d / time.Microsecond), Status: pgtype.Present}
+}
+
+// NewTimetz returns the time of day of t, at the offset of its location.
+func NewTimetz(t time.Time) (r {{$.ModelPackageName}}.Timetz) { r.Set(t); return }
+{{- end}}
 
 func NewInt2(i int16) pgtype.Int2 { return pgtype.Int2{Int: i, Status: pgtype.Present} }
 func NewInt4(i int32) pgtype.Int4 { return pgtype.Int4{Int: i, Status: pgtype.Present} }
@@ -29,6 +46,92 @@ func NewFloat4Array(f []float32) (fa pgtype.Float4Array)   { fa.Set(f); return }
 func NewFloat8(f float64) pgtype.Float8                    { return pgtype.Float8{Float: f, Status: pgtype.Present} }
 func NewFloat8Array(f []float64) (m pgtype.Float8Array)    { m.Set(f); return }
 
+func NewNumeric(i *big.Int, exp int32) pgtype.Numeric { return pgtype.Numeric{Int: i, Exp: exp, Status: pgtype.Present} }
+func NewNumericFromInt(i int64) pgtype.Numeric        { return NewNumeric(big.NewInt(i), 0) }
+func NewNumericFromString(s string) (n pgtype.Numeric) { n.Set(s); return }
+
+// NewNumericFromRat returns r exactly when it has a finite decimal expansion, and otherwise rounded to 20 decimal
+// places, e.g. for 1/3. A nil r is null.
+func NewNumericFromRat(r *big.Rat) pgtype.Numeric {
+	if r == nil {
+		return pgtype.Numeric{Status: pgtype.Null}
+	}
+	// The number of decimal places is the larger power of 2 or 5 in the denominator.
+	d := new(big.Int).Set(r.Denom())
+	var places [2]int
+	for k, f := range []int64{2, 5} {
+		q, m, b := new(big.Int), new(big.Int), big.NewInt(f)
+		for {
+			q.QuoRem(d, b, m)
+			if m.Sign() != 0 {
+				break
+			}
+			d.Set(q)
+			places[k]++
+		}
+	}
+	scale := places[0]
+	if places[1] > scale {
+		scale = places[1]
+	}
+	if d.Cmp(big.NewInt(1)) != 0 {
+		scale = 20
+	}
+	return NewNumericFromString(r.FloatString(scale))
+}
+
+// NewInet returns the address and network of n. A nil n is null.
+func NewInet(n *net.IPNet) pgtype.Inet {
+	if n == nil {
+		return pgtype.Inet{Status: pgtype.Null}
+	}
+	return pgtype.Inet{IPNet: n, Status: pgtype.Present}
+}
+
+// NewInetFromIP returns the host address ip. A nil ip is null.
+func NewInetFromIP(ip net.IP) pgtype.Inet {
+	if ip == nil {
+		return pgtype.Inet{Status: pgtype.Null}
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	return NewInet(&net.IPNet{IP: ip, Mask: net.CIDRMask(len(ip)*8, len(ip)*8)})
+}
+
+// NewInetFromString parses a host address, e.g. 192.168.0.1, or an address with its network, e.g. 192.168.0.1/24,
+// keeping the host bits as inet does.
+func NewInetFromString(s string) pgtype.Inet {
+	if !strings.Contains(s, "/") {
+		ip := net.ParseIP(s)
+		if ip == nil {
+			return pgtype.Inet{}
+		}
+		return NewInetFromIP(ip)
+	}
+	ip, n, err := net.ParseCIDR(s)
+	if err != nil {
+		return pgtype.Inet{}
+	}
+	if ip4 := ip.To4(); ip4 != nil {
+		ip = ip4
+	}
+	n.IP = ip
+	return NewInet(n)
+}
+
+func NewCIDR(n *net.IPNet) pgtype.CIDR { return pgtype.CIDR(NewInet(n)) }
+func NewCIDRFromString(s string) (c pgtype.CIDR) { c.Set(s); return }
+
+// NewMacaddr returns the address a. A nil a is null.
+func NewMacaddr(a net.HardwareAddr) pgtype.Macaddr {
+	if a == nil {
+		return pgtype.Macaddr{Status: pgtype.Null}
+	}
+	return pgtype.Macaddr{Addr: a, Status: pgtype.Present}
+}
+func NewMacaddrFromString(s string) (m pgtype.Macaddr) { m.Set(s); return }
+
 func NewUUIDV4() pgtype.UUID                  { return NewUUID(uuid.Must(uuid.NewV4())) }
 func NewUUID(id uuid.UUID) pgtype.UUID        { return pgtype.UUID{Bytes: [16]byte(id), Status: pgtype.Present} }
 func NewUUIDFromString(id string) pgtype.UUID { return pgtype.UUID{Bytes: uuid.FromStringOrNil(id), Status: pgtype.Present} }
@@ -2,6 +2,8 @@
 package nullzero
 
 import (
+	"math/big"
+	"net"
 	"time"
 
 	".."
@@ -17,6 +19,25 @@ func NewJSONB(b []byte) pgtype.JSONB { return pgtype.JSONB{Bytes: b, Status: nul
 
 func NewTimestamp(t time.Time) pgtype.Timestamp     { return pgtype.Timestamp{Time: t, Status: nullIf(t.IsZero())} }
 func NewTimestamptz(t time.Time) pgtype.Timestamptz { return pgtype.Timestamptz{Time: t, Status: nullIf(t.IsZero())} }
+func NewDate(t time.Time) pgtype.Date               { return pgtype.Date{Time: t, Status: nullIf(t.IsZero())} }
+func NewInterval(d time.Duration) pgtype.Interval {
+	i := types.NewInterval(d)
+	i.Status = nullIf(d == 0)
+	return i
+}
+{{- if .Data.Uses "time" "timetz"}}
+func NewTime(d time.Duration) {{$.ModelPackageName}}.Time {
+	t := types.NewTime(d)
+	t.Status = nullIf(d == 0)
+	return t
+}
+func NewTimetz(t time.Time) {{$.ModelPackageName}}.Timetz {
+	if t.IsZero() {
+		return {{$.ModelPackageName}}.Timetz{Status: pgtype.Null}
+	}
+	return types.NewTimetz(t)
+}
+{{- end}}
 
 func NewInt2(i int16) pgtype.Int2 { return pgtype.Int2{Int: i, Status: nullIf(i == 0)} }
 func NewInt4(i int32) pgtype.Int4 { return pgtype.Int4{Int: i, Status: nullIf(i == 0)} }
@@ -38,6 +59,61 @@ func NewFloat8Array(f []float64) (fa pgtype.Float8Array) {
 	return
 }
 
+func NewNumeric(i *big.Int, exp int32) pgtype.Numeric {
+	return pgtype.Numeric{Int: i, Exp: exp, Status: nullIf(i == nil || i.Sign() == 0)}
+}
+func NewNumericFromInt(i int64) pgtype.Numeric { return NewNumeric(big.NewInt(i), 0) }
+func NewNumericFromString(s string) pgtype.Numeric {
+	if s == "" {
+		return pgtype.Numeric{Status: pgtype.Null}
+	}
+	return types.NewNumericFromString(s)
+}
+func NewNumericFromRat(r *big.Rat) pgtype.Numeric {
+	if r == nil || r.Sign() == 0 {
+		return pgtype.Numeric{Status: pgtype.Null}
+	}
+	return types.NewNumericFromRat(r)
+}
+
+func NewInet(n *net.IPNet) pgtype.Inet {
+	if n == nil || n.IP == nil {
+		return pgtype.Inet{Status: pgtype.Null}
+	}
+	return types.NewInet(n)
+}
+func NewInetFromIP(ip net.IP) pgtype.Inet {
+	if ip == nil || ip.IsUnspecified() {
+		return pgtype.Inet{Status: pgtype.Null}
+	}
+	return types.NewInetFromIP(ip)
+}
+func NewInetFromString(s string) pgtype.Inet {
+	if s == "" {
+		return pgtype.Inet{Status: pgtype.Null}
+	}
+	return types.NewInetFromString(s)
+}
+func NewCIDR(n *net.IPNet) pgtype.CIDR { return pgtype.CIDR(NewInet(n)) }
+func NewCIDRFromString(s string) pgtype.CIDR {
+	if s == "" {
+		return pgtype.CIDR{Status: pgtype.Null}
+	}
+	return types.NewCIDRFromString(s)
+}
+func NewMacaddr(a net.HardwareAddr) pgtype.Macaddr {
+	if len(a) == 0 {
+		return pgtype.Macaddr{Status: pgtype.Null}
+	}
+	return types.NewMacaddr(a)
+}
+func NewMacaddrFromString(s string) pgtype.Macaddr {
+	if s == "" {
+		return pgtype.Macaddr{Status: pgtype.Null}
+	}
+	return types.NewMacaddrFromString(s)
+}
+
 func NewUUID(id uuid.UUID) pgtype.UUID { return pgtype.UUID{Bytes: [16]byte(id), Status: nullIf(id == uuid.Nil)} }
 func NewUUIDArray(ids []uuid.UUID) pgtype.UUIDArray {
 	if ids == nil || len(ids) == 0 {
@@ -4,6 +4,7 @@ package {{.PackageName}}
 import (
 	"encoding/hex"
 	"strconv"
+	"strings"
 
 	"github.com/jackc/pgx/pgtype"
 	"github.com/satori/go.uuid"
@@ -21,7 +22,51 @@ func BoolToString(v pgtype.Bool) string               { return strconv.FormatBoo
 func UUIDToString(v pgtype.UUID) string               { return uuid.FromBytesOrNil(v.Bytes[:]).String() }
 func TimestampToString(v pgtype.Timestamp) string     { return v.Time.String() }
 func TimestamptzToString(v pgtype.Timestamptz) string { return v.Time.String() }
+func DateToString(v pgtype.Date) string               { b, _ := v.EncodeText(nil, nil); return string(b) }
+func IntervalToString(v pgtype.Interval) string       { b, _ := v.EncodeText(nil, nil); return string(b) }
+func MacaddrToString(v pgtype.Macaddr) string         { return v.Addr.String() }
+
+// InetToString formats an inet as its address, followed by the size of its network unless it is a host address, e.g.
+// 192.168.0.1 or 192.168.0.1/24, as Postgres does.
+func InetToString(v pgtype.Inet) string {
+	n := IPNet(v)
+	if n == nil {
+		return ""
+	}
+	if ones, bits := n.Mask.Size(); ones == bits {
+		return n.IP.String()
+	}
+	return n.String()
+}
+
+// CIDRToString formats a cidr as its network, e.g. 192.168.0.0/24.
+func CIDRToString(v pgtype.CIDR) string {
+	if n := CIDRNet(v); n != nil {
+		return n.String()
+	}
+	return ""
+}
+{{- if .Data.Uses "time" "timetz"}}
+func TimeToString(v {{$.ModelPackageName}}.Time) string     { return v.String() }
+func TimetzToString(v {{$.ModelPackageName}}.Timetz) string { return v.String() }
+{{- end}}
+
+// NumericToString formats a numeric without trailing zeros, so equal values such as 1.5 and 1.50 format alike.
+func NumericToString(v pgtype.Numeric) string {
+	r := Rat(v)
+	if r == nil {
+		return ""
+	}
+	if v.Exp >= 0 {
+		return r.FloatString(0)
+	}
+	s := strings.TrimRight(r.FloatString(int(-v.Exp)), "0")
+	return strings.TrimSuffix(s, ".")
+}
 
 {{range .Data.Enums -}}
 func {{.ExportedName}}ToString(v {{$.ModelPackageName}}.{{.ExportedName}}) string {return v.String}
+{{end -}}
+{{range .Data.Composites -}}
+func {{.ExportedName}}ToString(v {{$.ModelPackageName}}.{{.ExportedName}}) string { b, _ := v.EncodeText(nil, nil); return string(b) }
 {{end -}}
\ No newline at end of file
@@ -2,6 +2,8 @@
 package {{.PackageName}}
 
 import (
+	"math/big"
+	"net"
 	"time"
 
 	"github.com/jackc/pgx/pgtype"
@@ -17,6 +19,21 @@ func JSONB(from pgtype.JSONB) (r []byte) { from.AssignTo(&r); return }
 
 func Time(from pgtype.Timestamp) (r time.Time)    { from.AssignTo(&r); return }
 func Time2(from pgtype.Timestamptz) (r time.Time) { from.AssignTo(&r); return }
+func Date(from pgtype.Date) (r time.Time)         { from.AssignTo(&r); return }
+
+// Duration returns an interval as a time.Duration, and whether it converts losslessly. Intervals with days or
+// months, whose length varies, don't, and neither do those which aren't Present; read their fields instead.
+func Duration(from pgtype.Interval) (time.Duration, bool) {
+	if from.Status != pgtype.Present || from.Days != 0 || from.Months != 0 {
+		return 0, false
+	}
+	return time.Duration(from.Microseconds) * time.Microsecond, true
+}
+{{- if .Data.Uses "time" "timetz"}}
+
+func TimeOfDay(from {{$.ModelPackageName}}.Time) time.Duration { return from.Duration() }
+func TimeOfDayTz(from {{$.ModelPackageName}}.Timetz) time.Time  { return from.Time() }
+{{- end}}
 
 func Int16(from pgtype.Int2) (r int16) { from.AssignTo(&r); return }
 func Int32(from pgtype.Int4) (r int32) { from.AssignTo(&r); return }
@@ -28,6 +45,36 @@ func Float32Slice(from pgtype.Float4Array) (r []float32) { from.AssignTo(&r); re
 func Float64(from pgtype.Float8) (r float64)             { from.AssignTo(&r); return }
 func Float64Slice(from pgtype.Float8Array) (r []float64) { from.AssignTo(&r); return }
 
+// Rat returns the exact value of a numeric, or nil when it isn't Present.
+func Rat(from pgtype.Numeric) *big.Rat {
+	if from.Status != pgtype.Present {
+		return nil
+	}
+	r := new(big.Rat).SetInt(from.Int)
+	exp := int64(from.Exp)
+	if exp < 0 {
+		exp = -exp
+	}
+	pow := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(exp), nil))
+	if from.Exp < 0 {
+		return r.Quo(r, pow)
+	}
+	return r.Mul(r, pow)
+}
+
+// IPNet returns the address and network of an inet, or nil when it isn't Present.
+func IPNet(from pgtype.Inet) *net.IPNet {
+	if from.Status != pgtype.Present {
+		return nil
+	}
+	return from.IPNet
+}
+
+// CIDRNet returns the network of a cidr, or nil when it isn't Present.
+func CIDRNet(from pgtype.CIDR) *net.IPNet { return IPNet(pgtype.Inet(from)) }
+
+func HardwareAddr(from pgtype.Macaddr) (r net.HardwareAddr) { from.AssignTo(&r); return }
+
 func UUID(id pgtype.UUID) uuid.UUID {
 	var b []byte
 	id.AssignTo(&b)